@@ -149,7 +149,9 @@ type RedirectError struct {
 	// servers.
 	Servers [][]network.HostPort
 
-	// CACert holds the certificate of the remote server.
+	// CACert holds the certificate of the remote server. It may
+	// hold several PEM certificates, any of which is accepted; this
+	// is used while a controller's CA certificate is being rotated.
 	CACert string
 }
 
@@ -45,6 +45,15 @@ func (*certPoolSuite) TestCreateCertPoolTestCert(c *gc.C) {
 	c.Assert(pool.Subjects(), gc.HasLen, 1)
 }
 
+func (*certPoolSuite) TestCreateCertPoolBundle(c *gc.C) {
+	expiry := time.Now().UTC().AddDate(10, 0, 0)
+	previousCACert, _, err := cert.NewCA("previous env name", "1", expiry)
+	c.Assert(err, jc.ErrorIsNil)
+	pool, err := api.CreateCertPool(testing.CACert + previousCACert)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(pool.Subjects(), gc.HasLen, 2)
+}
+
 func (s *certPoolSuite) TestCreateCertPoolNoDir(c *gc.C) {
 	certDir := filepath.Join(c.MkDir(), "missing")
 	s.PatchValue(api.CertDir, certDir)
@@ -107,6 +107,29 @@ func (info *Info) Validate() error {
 	return nil
 }
 
+// Clone returns a copy of info that shares no mutable state with it,
+// so that it can be customised, for example with a different Tag and
+// Password, without affecting the original.
+func (info *Info) Clone() *Info {
+	clone := *info
+	if info.Addrs != nil {
+		clone.Addrs = append([]string(nil), info.Addrs...)
+	}
+	if info.Macaroons != nil {
+		clone.Macaroons = make([]macaroon.Slice, len(info.Macaroons))
+		for i, ms := range info.Macaroons {
+			if ms == nil {
+				continue
+			}
+			clone.Macaroons[i] = make(macaroon.Slice, len(ms))
+			for j, m := range ms {
+				clone.Macaroons[i][j] = m.Clone()
+			}
+		}
+	}
+	return &clone
+}
+
 // DialOpts holds configuration parameters that control the
 // Dialing behavior when connecting to a controller.
 type DialOpts struct {
@@ -0,0 +1,51 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package api_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+	"gopkg.in/macaroon.v1"
+
+	"github.com/juju/juju/api"
+	jtesting "github.com/juju/juju/testing"
+)
+
+type infoSuite struct {
+	jtesting.BaseSuite
+}
+
+var _ = gc.Suite(&infoSuite{})
+
+func (s *infoSuite) TestClone(c *gc.C) {
+	mac, err := macaroon.New([]byte("root-key"), "id", "location")
+	c.Assert(err, jc.ErrorIsNil)
+	info := &api.Info{
+		Addrs:     []string{"0.1.2.3:17070", "0.1.2.4:17070"},
+		CACert:    jtesting.CACert,
+		ModelTag:  jtesting.ModelTag,
+		Tag:       names.NewUserTag("admin"),
+		Password:  "hunter2",
+		Macaroons: []macaroon.Slice{{mac}},
+	}
+	clone := info.Clone()
+	c.Assert(clone, jc.DeepEquals, info)
+
+	clone.Addrs[0] = "0.1.2.5:17070"
+	clone.Addrs = append(clone.Addrs, "0.1.2.6:17070")
+	clone.Tag = names.NewMachineTag("0")
+	clone.Password = "sekrit"
+	c.Assert(clone.Macaroons[0][0].AddFirstPartyCaveat("allow read"), jc.ErrorIsNil)
+
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070", "0.1.2.4:17070"})
+	c.Assert(info.Tag, gc.Equals, names.NewUserTag("admin"))
+	c.Assert(info.Password, gc.Equals, "hunter2")
+	c.Assert(info.Macaroons[0][0].Caveats(), gc.HasLen, 0)
+}
+
+func (s *infoSuite) TestCloneEmpty(c *gc.C) {
+	info := &api.Info{}
+	c.Assert(info.Clone(), jc.DeepEquals, info)
+}
@@ -12,7 +12,9 @@ import (
 	"gopkg.in/macaroon.v1"
 
 	"github.com/juju/juju/api/base"
+	apiwatcher "github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/description"
 	"github.com/juju/juju/core/migration"
 	"github.com/juju/juju/watcher"
 )
@@ -28,15 +30,30 @@ func NewClient(caller base.APICaller, newWatcher NewWatcherFunc) *Client {
 	}
 }
 
+// NewClientWithHeartbeat returns a new Client, as NewClient does,
+// whose Watch method returns watchers that check the API connection
+// according to the given heartbeat config.
+func NewClientWithHeartbeat(caller base.APICaller, newWatcher NewWatcherFunc, heartbeat HeartbeatConfig) (*Client, error) {
+	if err := heartbeat.Validate(); err != nil {
+		return nil, errors.Annotate(err, "invalid heartbeat config")
+	}
+	client := NewClient(caller, newWatcher)
+	client.heartbeat = &heartbeat
+	return client, nil
+}
+
 // Client describes the client side API for the MigrationMaster facade
 // (used by the migrationmaster worker).
 type Client struct {
 	caller     base.FacadeCaller
 	newWatcher NewWatcherFunc
+	heartbeat  *HeartbeatConfig
 }
 
 // Watch returns a watcher which reports when a migration is active
-// for the model associated with the API connection.
+// for the model associated with the API connection. If the client
+// was created with a heartbeat config, the watcher will die with an
+// error if the API server stops responding to pings.
 func (c *Client) Watch() (watcher.NotifyWatcher, error) {
 	var result params.NotifyWatchResult
 	err := c.caller.FacadeCall("Watch", nil, &result)
@@ -46,7 +63,50 @@ func (c *Client) Watch() (watcher.NotifyWatcher, error) {
 	if result.Error != nil {
 		return nil, result.Error
 	}
-	return c.newWatcher(c.caller.RawAPICaller(), result), nil
+	w := c.newWatcher(c.caller.RawAPICaller(), result)
+	if c.heartbeat == nil {
+		return w, nil
+	}
+	return newHeartbeatWatcher(c.caller.RawAPICaller(), w, *c.heartbeat)
+}
+
+// WatchMigrationStatus returns a watcher which delivers the status of
+// the latest migration for the model associated with the API
+// connection, each time that status changes. If the controller cannot
+// report migration status, the error satisfies errors.IsNotImplemented.
+func (c *Client) WatchMigrationStatus() (watcher.MigrationStatusWatcher, error) {
+	if c.caller.BestAPIVersion() < 2 {
+		return nil, errors.NotImplementedf("WatchMigrationStatus() (need V2+)")
+	}
+	var result params.NotifyWatchResult
+	err := c.caller.FacadeCall("WatchMigrationStatus", nil, &result)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return apiwatcher.NewMigrationStatusWatcher(c.caller.RawAPICaller(), result.NotifyWatcherId), nil
+}
+
+// WatchProgress returns a watcher whose changes each hold a
+// migration.Progress, serialized with Progress.Serialize, for the
+// current migration. Use migration.ParseProgress to decode them. If
+// the controller cannot report progress, the error satisfies
+// errors.IsNotImplemented, and only phase changes are available.
+func (c *Client) WatchProgress() (watcher.StringsWatcher, error) {
+	var result params.StringsWatchResult
+	err := c.caller.FacadeCall("WatchProgress", nil, &result)
+	if params.IsCodeNotImplemented(err) {
+		return nil, errors.NewNotImplemented(err, "migration progress")
+	}
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return apiwatcher.NewStringsWatcher(c.caller.RawAPICaller(), result), nil
 }
 
 // MigrationStatus returns the details and progress of the latest
@@ -105,7 +165,13 @@ func (c *Client) MigrationStatus() (migration.MigrationStatus, error) {
 }
 
 // SetPhase updates the phase of the currently active model migration.
+// If the migration can't move to the requested phase from its current
+// phase, an error satisfying params.IsCodeIllegalPhaseChange is
+// returned.
 func (c *Client) SetPhase(phase migration.Phase) error {
+	if parsed, ok := migration.ParsePhase(phase.String()); !ok || parsed != phase || phase == migration.UNKNOWN {
+		return errors.NotValidf("phase %d", phase)
+	}
 	args := params.SetMigrationPhaseArgs{
 		Phase: phase.String(),
 	}
@@ -121,6 +187,23 @@ func (c *Client) SetStatusMessage(message string) error {
 	return c.caller.FacadeCall("SetStatusMessage", args, nil)
 }
 
+// Abort aborts the model's current migration, recording the given
+// reason. If the migration has already finished, the error returned
+// satisfies params.IsCodeMigrationFinished. If the controller cannot
+// abort migrations, the error satisfies errors.IsNotImplemented.
+func (c *Client) Abort(reason string) error {
+	if reason == "" {
+		return errors.NotValidf("empty abort reason")
+	}
+	if c.caller.BestAPIVersion() < 2 {
+		return errors.NotImplementedf("Abort() (need V2+)")
+	}
+	args := params.AbortMigrationArgs{
+		Reason: reason,
+	}
+	return c.caller.FacadeCall("Abort", args, nil)
+}
+
 // ModelInfo return basic information about the model to migrated.
 func (c *Client) ModelInfo() (migration.ModelInfo, error) {
 	var info params.MigrationModelInfo
@@ -141,29 +224,86 @@ func (c *Client) ModelInfo() (migration.ModelInfo, error) {
 }
 
 // Prechecks verifies that the source controller and model are healthy
-// and able to participate in a migration.
+// and able to participate in a migration. If several checks fail, the
+// returned error describes each failure on its own line.
 func (c *Client) Prechecks() error {
 	return c.caller.FacadeCall("Prechecks", nil, nil)
 }
 
+// Validate reports what migrating the model associated with the API
+// connection to the given target would involve, including whether the
+// target is compatible, without changing the state of any migration.
+// If the controller cannot validate migrations, the error satisfies
+// errors.IsNotImplemented.
+func (c *Client) Validate(target migration.TargetInfo) (migration.ValidationReport, error) {
+	var empty migration.ValidationReport
+	if err := target.Validate(); err != nil {
+		return empty, errors.Trace(err)
+	}
+	if c.caller.BestAPIVersion() < 2 {
+		return empty, errors.NotImplementedf("Validate() (need V2+)")
+	}
+	var macsJSON []byte
+	if len(target.Macaroons) > 0 {
+		var err error
+		macsJSON, err = json.Marshal(target.Macaroons)
+		if err != nil {
+			return empty, errors.Annotate(err, "marshalling macaroons")
+		}
+	}
+	args := params.ValidateMigrationArgs{
+		TargetInfo: params.MigrationTargetInfo{
+			ControllerTag: target.ControllerTag.String(),
+			Addrs:         target.Addrs,
+			CACert:        target.CACert,
+			AuthTag:       target.AuthTag.String(),
+			Password:      target.Password,
+			Macaroons:     string(macsJSON),
+		},
+	}
+	var report params.MigrationValidationReport
+	err := c.caller.FacadeCall("Validate", args, &report)
+	if params.IsCodeNotImplemented(err) {
+		return empty, errors.NewNotImplemented(err, "migration validation")
+	}
+	if err != nil {
+		return empty, errors.Trace(err)
+	}
+	tools, err := convertTools(report.Tools)
+	if err != nil {
+		return empty, errors.Trace(err)
+	}
+	return migration.ValidationReport{
+		TransferSize: report.TransferSize,
+		MachineCount: report.MachineCount,
+		UnitCount:    report.UnitCount,
+		CharmCount:   report.CharmCount,
+		Charms:       report.Charms,
+		Tools:        tools,
+		Warnings:     report.Warnings,
+	}, nil
+}
+
 // Export returns a serialized representation of the model associated
 // with the API connection. The charms used by the model are also
-// returned.
+// returned. An error is returned if the serialized model is empty or
+// in a format version that this client does not understand.
 func (c *Client) Export() (migration.SerializedModel, error) {
 	var serialized params.SerializedModel
 	err := c.caller.FacadeCall("Export", nil, &serialized)
 	if err != nil {
 		return migration.SerializedModel{}, err
 	}
+	if len(serialized.Bytes) == 0 {
+		return migration.SerializedModel{}, errors.New("empty serialized model")
+	}
+	if err := description.CheckVersion(serialized.Bytes); err != nil {
+		return migration.SerializedModel{}, errors.Annotate(err, "checking serialized model")
+	}
 
-	// Convert tools info to output map.
-	tools := make(map[version.Binary]string)
-	for _, toolsInfo := range serialized.Tools {
-		v, err := version.ParseBinary(toolsInfo.Version)
-		if err != nil {
-			return migration.SerializedModel{}, errors.Annotate(err, "error parsing tools version")
-		}
-		tools[v] = toolsInfo.URI
+	tools, err := convertTools(serialized.Tools)
+	if err != nil {
+		return migration.SerializedModel{}, errors.Trace(err)
 	}
 
 	return migration.SerializedModel{
@@ -248,3 +388,17 @@ func groupTagIds(tagStrs []string) ([]string, []string, error) {
 	}
 	return machines, units, nil
 }
+
+// convertTools converts the tools info returned by the API to a map
+// of tools version to URI.
+func convertTools(in []params.SerializedModelTools) (map[version.Binary]string, error) {
+	tools := make(map[version.Binary]string)
+	for _, toolsInfo := range in {
+		v, err := version.ParseBinary(toolsInfo.Version)
+		if err != nil {
+			return nil, errors.Annotate(err, "error parsing tools version")
+		}
+		tools[v] = toolsInfo.URI
+	}
+	return tools, nil
+}
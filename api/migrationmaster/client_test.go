@@ -11,6 +11,7 @@ import (
 	jujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
+	"github.com/juju/utils/clock"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 	"gopkg.in/juju/names.v2"
@@ -19,9 +20,15 @@ import (
 	"github.com/juju/juju/api/base"
 	apitesting "github.com/juju/juju/api/base/testing"
 	"github.com/juju/juju/api/migrationmaster"
+	"github.com/juju/juju/api/migrationmaster/migrationmastertest"
+	apiwatcher "github.com/juju/juju/api/watcher"
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/core/migration"
+	"github.com/juju/juju/rpc"
+	coretesting "github.com/juju/juju/testing"
 	"github.com/juju/juju/watcher"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/workertest"
 )
 
 type ClientSuite struct {
@@ -62,6 +69,357 @@ func (s *ClientSuite) TestWatchCallError(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "boom")
 }
 
+func (s *ClientSuite) TestErrorClassification(c *gc.C) {
+	for i, test := range []struct {
+		err       error
+		transient bool
+	}{{
+		err: errors.New("boom"),
+	}, {
+		err: &params.Error{Message: "boom"},
+	}, {
+		err: &params.Error{Code: params.CodeIllegalPhaseChange, Message: "boom"},
+	}, {
+		err:       &params.Error{Code: params.CodeTryAgain, Message: "boom"},
+		transient: true,
+	}, {
+		err:       errors.Annotate(&params.Error{Code: params.CodeRetry, Message: "boom"}, "wrapped"),
+		transient: true,
+	}, {
+		err:       rpc.ErrShutdown,
+		transient: true,
+	}, {
+		err:       errors.Trace(rpc.ErrShutdown),
+		transient: true,
+	}} {
+		c.Logf("test %d: %v", i, test.err)
+		apiCaller := apitesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+			return test.err
+		})
+		client := migrationmaster.NewClient(apiCaller, nil)
+		_, err := client.Watch()
+		c.Check(migrationmaster.IsTransient(err), gc.Equals, test.transient)
+		c.Check(migrationmaster.IsFatal(err), gc.Equals, !test.transient)
+		c.Check(errors.Cause(err), gc.Equals, errors.Cause(test.err))
+	}
+	c.Check(migrationmaster.IsTransient(nil), jc.IsFalse)
+	c.Check(migrationmaster.IsFatal(nil), jc.IsFalse)
+}
+
+func (s *ClientSuite) TestNewClientWithHeartbeatInvalid(c *gc.C) {
+	_, err := migrationmaster.NewClientWithHeartbeat(nil, nil, migrationmaster.HeartbeatConfig{
+		Clock:  clock.WallClock,
+		Period: time.Second,
+	})
+	c.Assert(err, gc.ErrorMatches, "invalid heartbeat config: non-positive Timeout not valid")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *ClientSuite) TestWatchHeartbeat(c *gc.C) {
+	pinged := make(chan struct{}, 1)
+	client := s.heartbeatClient(c, func() error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return nil
+	}, coretesting.LongWait)
+
+	w, err := client.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	for i := 0; i < 2; i++ {
+		select {
+		case <-pinged:
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for ping")
+		}
+	}
+	workertest.CheckAlive(c, w)
+	workertest.CleanKill(c, w)
+}
+
+func (s *ClientSuite) TestWatchHeartbeatPingError(c *gc.C) {
+	client := s.heartbeatClient(c, func() error {
+		return errors.New("boom")
+	}, coretesting.LongWait)
+
+	w, err := client.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	err = workertest.CheckKilled(c, w)
+	c.Assert(err, gc.ErrorMatches, "migration watcher heartbeat failed: boom")
+	c.Check(err, jc.Satisfies, migrationmaster.IsTransient)
+	c.Check(errors.Cause(err), gc.ErrorMatches, "boom")
+}
+
+func (s *ClientSuite) TestWatchHeartbeatStalledConnection(c *gc.C) {
+	stalled := make(chan struct{})
+	defer close(stalled)
+	client := s.heartbeatClient(c, func() error {
+		<-stalled
+		return nil
+	}, 10*time.Millisecond)
+
+	w, err := client.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	err = workertest.CheckKilled(c, w)
+	c.Assert(err, gc.ErrorMatches, "migration watcher heartbeat timed out after 10ms")
+	c.Check(err, jc.Satisfies, migrationmaster.IsTransient)
+}
+
+// heartbeatClient returns a Client, connected to a fake MigrationMaster
+// server, whose watchers ping every millisecond using the supplied ping
+// func and the given timeout.
+func (s *ClientSuite) heartbeatClient(c *gc.C, ping func() error, timeout time.Duration) *migrationmaster.Client {
+	server := migrationmastertest.NewServer(params.MasterMigrationStatus{Phase: "NONE"})
+	serverCaller := server.APICaller()
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		if objType == "Pinger" {
+			c.Check(request, gc.Equals, "Ping")
+			return ping()
+		}
+		return serverCaller.APICall(objType, version, id, request, arg, result)
+	})
+	client, err := migrationmaster.NewClientWithHeartbeat(apiCaller, apiwatcher.NewNotifyWatcher, migrationmaster.HeartbeatConfig{
+		Clock:   clock.WallClock,
+		Period:  time.Millisecond,
+		Timeout: timeout,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	return client
+}
+
+func (s *ClientSuite) TestWatchMigrationStatus(c *gc.C) {
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		switch request {
+		case "WatchMigrationStatus":
+			*(result.(*params.NotifyWatchResult)) = params.NotifyWatchResult{
+				NotifyWatcherId: "abc",
+			}
+		case "Next":
+			// The full success case is tested in api/watcher.
+			return errors.New("boom")
+		case "Stop":
+		}
+		return nil
+	})
+
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 2}, nil)
+	w, err := client.WatchMigrationStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+
+	errC := make(chan error)
+	go func() {
+		errC <- w.Wait()
+	}()
+
+	select {
+	case err := <-errC:
+		c.Assert(err, gc.ErrorMatches, "boom")
+		expectedCalls := []jujutesting.StubCall{
+			{"MigrationMaster.WatchMigrationStatus", []interface{}{"", nil}},
+			{"MigrationStatusWatcher.Next", []interface{}{"abc", nil}},
+			{"MigrationStatusWatcher.Stop", []interface{}{"abc", nil}},
+		}
+		// The Stop API call happens in a separate goroutine which
+		// might execute after the worker has exited so wait for the
+		// expected calls to arrive.
+		for a := coretesting.LongAttempt.Start(); a.Next(); {
+			if len(stub.Calls()) >= len(expectedCalls) {
+				return
+			}
+		}
+		stub.CheckCalls(c, expectedCalls)
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("timed out waiting for watcher to die")
+	}
+}
+
+func (s *ClientSuite) TestWatchMigrationStatusError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		*(result.(*params.NotifyWatchResult)) = params.NotifyWatchResult{
+			Error: &params.Error{Message: "boom"},
+		}
+		return nil
+	})
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 2}, nil)
+	_, err := client.WatchMigrationStatus()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *ClientSuite) TestWatchMigrationStatusV1(c *gc.C) {
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		return nil
+	})
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 1}, nil)
+	_, err := client.WatchMigrationStatus()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	stub.CheckNoCalls(c)
+}
+
+func (s *ClientSuite) TestWatchProgress(c *gc.C) {
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		switch request {
+		case "WatchProgress":
+			*(result.(*params.StringsWatchResult)) = params.StringsWatchResult{
+				StringsWatcherId: "abc",
+			}
+		case "Next":
+			// The full success case is tested in api/watcher.
+			return errors.New("boom")
+		case "Stop":
+		}
+		return nil
+	})
+
+	client := migrationmaster.NewClient(apiCaller, nil)
+	w, err := client.WatchProgress()
+	c.Assert(err, jc.ErrorIsNil)
+	defer worker.Stop(w)
+
+	errC := make(chan error)
+	go func() {
+		errC <- w.Wait()
+	}()
+
+	select {
+	case err := <-errC:
+		c.Assert(err, gc.ErrorMatches, "boom")
+		stub.CheckCall(c, 0, "MigrationMaster.WatchProgress", "", nil)
+	case <-time.After(coretesting.LongWait):
+		c.Fatal("timed out waiting for watcher to die")
+	}
+}
+
+func (s *ClientSuite) TestWatchProgressError(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		*(result.(*params.StringsWatchResult)) = params.StringsWatchResult{
+			Error: &params.Error{Message: "boom"},
+		}
+		return nil
+	})
+	client := migrationmaster.NewClient(apiCaller, nil)
+	_, err := client.WatchProgress()
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *ClientSuite) TestWatchProgressNotImplemented(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		return &params.Error{
+			Message: "no such request - method MigrationMaster.WatchProgress is not implemented",
+			Code:    params.CodeNotImplemented,
+		}
+	})
+	client := migrationmaster.NewClient(apiCaller, nil)
+	_, err := client.WatchProgress()
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
+
+func (s *ClientSuite) TestValidate(c *gc.C) {
+	controllerTag := names.NewControllerTag(utils.MustNewUUID().String())
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		*(result.(*params.MigrationValidationReport)) = params.MigrationValidationReport{
+			TransferSize: 1024,
+			MachineCount: 2,
+			UnitCount:    3,
+			CharmCount:   1,
+			Charms:       []string{"cs:foo-1"},
+			Tools: []params.SerializedModelTools{
+				{"2.0.0-xenial-amd64", "/tools/0"},
+			},
+			Warnings: []string{"target is running an older version"},
+		}
+		return nil
+	})
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 2}, nil)
+	report, err := client.Validate(migration.TargetInfo{
+		ControllerTag: controllerTag,
+		Addrs:         []string{"2.2.2.2:2"},
+		CACert:        "cert",
+		AuthTag:       names.NewUserTag("admin"),
+		Password:      "secret",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	stub.CheckCalls(c, []jujutesting.StubCall{
+		{"MigrationMaster.Validate", []interface{}{"", params.ValidateMigrationArgs{
+			TargetInfo: params.MigrationTargetInfo{
+				ControllerTag: controllerTag.String(),
+				Addrs:         []string{"2.2.2.2:2"},
+				CACert:        "cert",
+				AuthTag:       names.NewUserTag("admin").String(),
+				Password:      "secret",
+			},
+		}}},
+	})
+	c.Assert(report, jc.DeepEquals, migration.ValidationReport{
+		TransferSize: 1024,
+		MachineCount: 2,
+		UnitCount:    3,
+		CharmCount:   1,
+		Charms:       []string{"cs:foo-1"},
+		Tools: map[version.Binary]string{
+			version.MustParseBinary("2.0.0-xenial-amd64"): "/tools/0",
+		},
+		Warnings: []string{"target is running an older version"},
+	})
+}
+
+func (s *ClientSuite) TestValidateInvalidTarget(c *gc.C) {
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		return nil
+	})
+	client := migrationmaster.NewClient(apiCaller, nil)
+	_, err := client.Validate(migration.TargetInfo{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	stub.CheckNoCalls(c)
+}
+
+func (s *ClientSuite) TestValidateNotImplemented(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		return &params.Error{
+			Message: "no such request - method MigrationMaster.Validate is not implemented",
+			Code:    params.CodeNotImplemented,
+		}
+	})
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 2}, nil)
+	_, err := client.Validate(migration.TargetInfo{
+		ControllerTag: names.NewControllerTag(utils.MustNewUUID().String()),
+		Addrs:         []string{"2.2.2.2:2"},
+		CACert:        "cert",
+		AuthTag:       names.NewUserTag("admin"),
+		Password:      "secret",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+}
+
+func (s *ClientSuite) TestValidateV1(c *gc.C) {
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		return nil
+	})
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 1}, nil)
+	_, err := client.Validate(migration.TargetInfo{
+		ControllerTag: names.NewControllerTag(utils.MustNewUUID().String()),
+		Addrs:         []string{"2.2.2.2:2"},
+		CACert:        "cert",
+		AuthTag:       names.NewUserTag("admin"),
+		Password:      "secret",
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	stub.CheckNoCalls(c)
+}
+
 func (s *ClientSuite) TestMigrationStatus(c *gc.C) {
 	mac, err := macaroon.New([]byte("secret"), "id", "location")
 	c.Assert(err, jc.ErrorIsNil)
@@ -139,6 +497,31 @@ func (s *ClientSuite) TestSetPhaseError(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "boom")
 }
 
+func (s *ClientSuite) TestSetPhaseInvalid(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		c.Fatalf("API should not be called")
+		return nil
+	})
+	client := migrationmaster.NewClient(apiCaller, nil)
+	for _, phase := range []migration.Phase{migration.UNKNOWN, migration.Phase(-1), migration.Phase(999)} {
+		err := client.SetPhase(phase)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+	}
+}
+
+func (s *ClientSuite) TestSetPhaseIllegalChange(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		return &params.Error{
+			Message: "failed to set phase: illegal phase change: QUIESCE -> SUCCESS",
+			Code:    params.CodeIllegalPhaseChange,
+		}
+	})
+	client := migrationmaster.NewClient(apiCaller, nil)
+	err := client.SetPhase(migration.SUCCESS)
+	c.Assert(err, gc.ErrorMatches, "failed to set phase: illegal phase change: QUIESCE -> SUCCESS")
+	c.Assert(err, jc.Satisfies, params.IsCodeIllegalPhaseChange)
+}
+
 func (s *ClientSuite) TestSetStatusMessage(c *gc.C) {
 	var stub jujutesting.Stub
 	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
@@ -163,6 +546,57 @@ func (s *ClientSuite) TestSetStatusMessageError(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "boom")
 }
 
+func (s *ClientSuite) TestAbort(c *gc.C) {
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		return nil
+	})
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 2}, nil)
+	err := client.Abort("target unreachable")
+	c.Assert(err, jc.ErrorIsNil)
+	expectedArg := params.AbortMigrationArgs{Reason: "target unreachable"}
+	stub.CheckCalls(c, []jujutesting.StubCall{
+		{"MigrationMaster.Abort", []interface{}{"", expectedArg}},
+	})
+}
+
+func (s *ClientSuite) TestAbortNoReason(c *gc.C) {
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		return nil
+	})
+	client := migrationmaster.NewClient(apiCaller, nil)
+	err := client.Abort("")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	stub.CheckNoCalls(c)
+}
+
+func (s *ClientSuite) TestAbortFinished(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(string, int, string, string, interface{}, interface{}) error {
+		return &params.Error{
+			Code:    params.CodeMigrationFinished,
+			Message: "migration already finished in phase DONE",
+		}
+	})
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 2}, nil)
+	err := client.Abort("too late")
+	c.Assert(err, jc.Satisfies, params.IsCodeMigrationFinished)
+}
+
+func (s *ClientSuite) TestAbortV1(c *gc.C) {
+	var stub jujutesting.Stub
+	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
+		stub.AddCall(objType+"."+request, id, arg)
+		return nil
+	})
+	client := migrationmaster.NewClient(apitesting.BestVersionCaller{APICallerFunc: apiCaller, BestVersion: 1}, nil)
+	err := client.Abort("target unreachable")
+	c.Assert(err, jc.Satisfies, errors.IsNotImplemented)
+	stub.CheckNoCalls(c)
+}
+
 func (s *ClientSuite) TestModelInfo(c *gc.C) {
 	var stub jujutesting.Stub
 	owner := names.NewUserTag("owner")
@@ -210,7 +644,7 @@ func (s *ClientSuite) TestExport(c *gc.C) {
 		stub.AddCall(objType+"."+request, id, arg)
 		out := result.(*params.SerializedModel)
 		*out = params.SerializedModel{
-			Bytes:  []byte("foo"),
+			Bytes:  []byte("version: 1\n"),
 			Charms: []string{"cs:foo-1"},
 			Tools: []params.SerializedModelTools{{
 				Version: "2.0.0-trusty-amd64",
@@ -226,7 +660,7 @@ func (s *ClientSuite) TestExport(c *gc.C) {
 		{"MigrationMaster.Export", []interface{}{"", nil}},
 	})
 	c.Assert(out, gc.DeepEquals, migration.SerializedModel{
-		Bytes:  []byte("foo"),
+		Bytes:  []byte("version: 1\n"),
 		Charms: []string{"cs:foo-1"},
 		Tools: map[version.Binary]string{
 			version.MustParseBinary("2.0.0-trusty-amd64"): "/tools/0",
@@ -243,6 +677,28 @@ func (s *ClientSuite) TestExportError(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "blam")
 }
 
+func (s *ClientSuite) TestExportEmpty(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(_ string, _ int, _, _ string, _, result interface{}) error {
+		*(result.(*params.SerializedModel)) = params.SerializedModel{}
+		return nil
+	})
+	client := migrationmaster.NewClient(apiCaller, nil)
+	_, err := client.Export()
+	c.Assert(err, gc.ErrorMatches, "empty serialized model")
+}
+
+func (s *ClientSuite) TestExportUnknownVersion(c *gc.C) {
+	apiCaller := apitesting.APICallerFunc(func(_ string, _ int, _, _ string, _, result interface{}) error {
+		*(result.(*params.SerializedModel)) = params.SerializedModel{
+			Bytes: []byte("version: 999\n"),
+		}
+		return nil
+	})
+	client := migrationmaster.NewClient(apiCaller, nil)
+	_, err := client.Export()
+	c.Assert(err, gc.ErrorMatches, "checking serialized model: version 999 not valid")
+}
+
 func (s *ClientSuite) TestReap(c *gc.C) {
 	var stub jujutesting.Stub
 	apiCaller := apitesting.APICallerFunc(func(objType string, version int, id, request string, arg, result interface{}) error {
@@ -0,0 +1,79 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster
+
+import (
+	"net"
+
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/rpc"
+)
+
+// IsTransient reports whether err, as returned by the Client or by one
+// of its watchers, was caused by a problem with the API connection, or
+// was reported by the API server as temporary. Such errors may go away
+// if the caller reconnects and tries again.
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if isHeartbeatError(err) || rpc.IsShutdownErr(err) {
+		return true
+	}
+	if _, ok := errors.Cause(err).(net.Error); ok {
+		return true
+	}
+	switch params.ErrCode(err) {
+	case params.CodeTryAgain, params.CodeRetry, params.CodeExcessiveContention:
+		return true
+	}
+	return false
+}
+
+// IsFatal reports whether err, as returned by the Client or by one of
+// its watchers, is not transient: it was reported by the
+// MigrationMaster facade, or the client could not use the response.
+// Trying again will not help, so the migration should be aborted.
+func IsFatal(err error) bool {
+	return err != nil && !IsTransient(err)
+}
+
+// heartbeatError is the error with which a heartbeat watcher dies
+// when the API connection fails a health check. Its cause is the
+// error returned by the ping, if there was one.
+type heartbeatError struct {
+	message string
+	cause   error
+}
+
+// Error is part of the error interface.
+func (e *heartbeatError) Error() string {
+	return e.message
+}
+
+// Cause returns the error returned by the ping, if any.
+func (e *heartbeatError) Cause() error {
+	return e.cause
+}
+
+// isHeartbeatError reports whether err is, or wraps, a
+// *heartbeatError.
+func isHeartbeatError(err error) bool {
+	type wrapper interface {
+		Underlying() error
+	}
+	for err != nil {
+		if _, ok := err.(*heartbeatError); ok {
+			return true
+		}
+		w, ok := err.(wrapper)
+		if !ok {
+			return false
+		}
+		err = w.Underlying()
+	}
+	return false
+}
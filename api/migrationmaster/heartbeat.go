@@ -0,0 +1,133 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/api/base"
+	"github.com/juju/juju/watcher"
+	"github.com/juju/juju/worker"
+	"github.com/juju/juju/worker/catacomb"
+)
+
+// HeartbeatConfig defines how a migration watcher checks that its
+// API connection is still alive while it waits for changes.
+type HeartbeatConfig struct {
+	// Clock is used to time pings and their responses.
+	Clock clock.Clock
+
+	// Period is the time between the end of one ping and the
+	// start of the next.
+	Period time.Duration
+
+	// Timeout is how long a ping may take before the watcher
+	// gives up on the connection and dies.
+	Timeout time.Duration
+}
+
+// Validate returns an error if the config cannot be used.
+func (config HeartbeatConfig) Validate() error {
+	if config.Clock == nil {
+		return errors.NotValidf("nil Clock")
+	}
+	if config.Period <= 0 {
+		return errors.NotValidf("non-positive Period")
+	}
+	if config.Timeout <= 0 {
+		return errors.NotValidf("non-positive Timeout")
+	}
+	return nil
+}
+
+// newHeartbeatWatcher returns a watcher which delivers the changes
+// of the supplied watcher, and which pings the API server across
+// caller according to config. If a ping fails, or does not complete
+// within the timeout, the watcher is stopped with an error.
+func newHeartbeatWatcher(
+	caller base.APICaller, w watcher.NotifyWatcher, config HeartbeatConfig,
+) (watcher.NotifyWatcher, error) {
+	hw := &heartbeatWatcher{
+		watcher: w,
+		ping: func() error {
+			return caller.APICall("Pinger", caller.BestFacadeVersion("Pinger"), "", "Ping", nil, nil)
+		},
+		config: config,
+	}
+	err := catacomb.Invoke(catacomb.Plan{
+		Site: &hw.catacomb,
+		Work: hw.loop,
+		Init: []worker.Worker{w},
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return hw, nil
+}
+
+type heartbeatWatcher struct {
+	catacomb catacomb.Catacomb
+	watcher  watcher.NotifyWatcher
+	ping     func() error
+	config   HeartbeatConfig
+}
+
+// Changes is part of the watcher.NotifyWatcher interface.
+func (w *heartbeatWatcher) Changes() watcher.NotifyChannel {
+	return w.watcher.Changes()
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *heartbeatWatcher) Kill() {
+	w.catacomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *heartbeatWatcher) Wait() error {
+	return w.catacomb.Wait()
+}
+
+func (w *heartbeatWatcher) loop() error {
+	for {
+		select {
+		case <-w.catacomb.Dying():
+			return w.catacomb.ErrDying()
+		case <-w.config.Clock.After(w.config.Period):
+		}
+		if err := w.checkConnection(); err != nil {
+			return errors.Trace(err)
+		}
+	}
+}
+
+// checkConnection pings the API server, returning a *heartbeatError
+// if the ping fails or takes longer than the configured timeout.
+func (w *heartbeatWatcher) checkConnection() error {
+	// The result channel is buffered so that a ping which completes
+	// after we've stopped waiting for it doesn't leak its goroutine.
+	result := make(chan error, 1)
+	go func() {
+		result <- w.ping()
+	}()
+	select {
+	case <-w.catacomb.Dying():
+		return w.catacomb.ErrDying()
+	case err := <-result:
+		if err == nil {
+			return nil
+		}
+		return &heartbeatError{
+			message: "migration watcher heartbeat failed: " + err.Error(),
+			cause:   err,
+		}
+	case <-w.config.Clock.After(w.config.Timeout):
+		return &heartbeatError{
+			message: fmt.Sprintf("migration watcher heartbeat timed out after %s", w.config.Timeout),
+		}
+	}
+}
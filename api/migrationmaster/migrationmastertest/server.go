@@ -0,0 +1,335 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+// Package migrationmastertest provides an in-process fake of the
+// MigrationMaster API facade, so that code driving a
+// migrationmaster.Client can be tested end-to-end without a
+// controller.
+package migrationmastertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	jujutesting "github.com/juju/testing"
+
+	"github.com/juju/juju/api/base"
+	apitesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/migrationmaster"
+	apiwatcher "github.com/juju/juju/api/watcher"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/migration"
+)
+
+const (
+	facadeName          = "MigrationMaster"
+	notifyWatcherFacade = "NotifyWatcher"
+	statusWatcherFacade = "MigrationStatusWatcher"
+)
+
+// Server is a fake MigrationMaster facade, together with the watcher
+// facades used by its clients. It keeps the state of a single
+// migration, which clients may read and change through the API.
+//
+// Calls to the MigrationMaster facade are recorded on the embedded
+// Stub as "MigrationMaster.<Method>", with the call's arguments.
+// Errors set with the Stub's SetErrors are returned, in order, by
+// those calls in place of their usual results. Calls to the watcher
+// facades are not recorded, as they are made concurrently by the
+// client watchers.
+type Server struct {
+	*jujutesting.Stub
+
+	mu            sync.Mutex
+	status        params.MasterMigrationStatus
+	statusMessage string
+	modelInfo     params.MigrationModelInfo
+	serialized    params.SerializedModel
+	minionReports params.MinionReports
+
+	lastWatcherId int
+	watchers      map[string]*fakeWatcher
+}
+
+// NewServer returns a new Server reporting the given migration status.
+func NewServer(status params.MasterMigrationStatus) *Server {
+	return &Server{
+		Stub:     &jujutesting.Stub{},
+		status:   status,
+		watchers: make(map[string]*fakeWatcher),
+	}
+}
+
+// APICaller returns a base.APICaller which sends its calls to the
+// server.
+func (s *Server) APICaller() base.APICaller {
+	return apitesting.APICallerFunc(s.call)
+}
+
+// NewClient returns a migrationmaster.Client connected to the server,
+// using the real API watcher implementations.
+func (s *Server) NewClient() *migrationmaster.Client {
+	return migrationmaster.NewClient(s.APICaller(), apiwatcher.NewNotifyWatcher)
+}
+
+// SetStatus replaces the migration status reported by the server, and
+// notifies any migration status watchers.
+func (s *Server) SetStatus(status params.MasterMigrationStatus) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+	s.notifyLocked(statusWatcherFacade, "WatchMigrationStatus")
+}
+
+// Status returns the migration status currently held by the server.
+func (s *Server) Status() params.MasterMigrationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.status
+}
+
+// StatusMessage returns the last status message set by a client.
+func (s *Server) StatusMessage() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.statusMessage
+}
+
+// SetModelInfo sets the result of the ModelInfo call.
+func (s *Server) SetModelInfo(info params.MigrationModelInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.modelInfo = info
+}
+
+// SetSerializedModel sets the result of the Export call.
+func (s *Server) SetSerializedModel(serialized params.SerializedModel) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.serialized = serialized
+}
+
+// SetMinionReports sets the result of the MinionReports call. It
+// does not notify minion report watchers; use NotifyMinionReports
+// for that.
+func (s *Server) SetMinionReports(reports params.MinionReports) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.minionReports = reports
+}
+
+// NotifyMigration sends a change to the watchers returned by Watch.
+func (s *Server) NotifyMigration() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifyLocked(notifyWatcherFacade, "Watch")
+}
+
+// NotifyMinionReports sends a change to the watchers returned by
+// WatchMinionReports.
+func (s *Server) NotifyMinionReports() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.notifyLocked(notifyWatcherFacade, "WatchMinionReports")
+}
+
+func (s *Server) notifyLocked(facade, source string) {
+	for _, w := range s.watchers {
+		if w.facade == facade && w.source == source {
+			w.notify()
+		}
+	}
+}
+
+func (s *Server) call(objType string, version int, id, request string, arg, result interface{}) error {
+	switch objType {
+	case facadeName:
+		s.AddCall(objType+"."+request, arg)
+		if err := s.NextErr(); err != nil {
+			return err
+		}
+		return s.callFacade(request, arg, result)
+	case notifyWatcherFacade, statusWatcherFacade:
+		return s.callWatcher(objType, id, request, result)
+	}
+	return &params.Error{
+		Code:    params.CodeNotImplemented,
+		Message: fmt.Sprintf("unknown object type %q", objType),
+	}
+}
+
+func (s *Server) callFacade(request string, arg, result interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch request {
+	case "Watch", "WatchMinionReports":
+		id := s.newWatcherLocked(notifyWatcherFacade, request)
+		return respond(result, params.NotifyWatchResult{NotifyWatcherId: id})
+	case "WatchMigrationStatus":
+		id := s.newWatcherLocked(statusWatcherFacade, request)
+		s.watchers[id].notify()
+		return respond(result, params.NotifyWatchResult{NotifyWatcherId: id})
+	case "MigrationStatus":
+		return respond(result, s.status)
+	case "SetPhase":
+		return s.setPhaseLocked(arg.(params.SetMigrationPhaseArgs).Phase)
+	case "SetStatusMessage":
+		s.statusMessage = arg.(params.SetMigrationStatusMessageArgs).Message
+		return nil
+	case "Abort":
+		return s.abortLocked(arg.(params.AbortMigrationArgs).Reason)
+	case "ModelInfo":
+		return respond(result, s.modelInfo)
+	case "Export":
+		return respond(result, s.serialized)
+	case "MinionReports":
+		return respond(result, s.minionReports)
+	case "Prechecks", "Reap":
+		return nil
+	}
+	return &params.Error{
+		Code:    params.CodeNotImplemented,
+		Message: fmt.Sprintf("unknown method %q", request),
+	}
+}
+
+func (s *Server) setPhaseLocked(phaseName string) error {
+	phase, ok := migration.ParsePhase(phaseName)
+	if !ok {
+		return fmt.Errorf("invalid phase: %q", phaseName)
+	}
+	current, _ := migration.ParsePhase(s.status.Phase)
+	if !current.CanTransitionTo(phase) {
+		return &params.Error{
+			Code:    params.CodeIllegalPhaseChange,
+			Message: fmt.Sprintf("failed to set phase: illegal phase change: %s -> %s", current, phase),
+		}
+	}
+	s.status.Phase = phase.String()
+	s.notifyLocked(statusWatcherFacade, "WatchMigrationStatus")
+	return nil
+}
+
+func (s *Server) abortLocked(reason string) error {
+	if reason == "" {
+		return &params.Error{Message: "empty abort reason not valid"}
+	}
+	current, _ := migration.ParsePhase(s.status.Phase)
+	if current.IsTerminal() {
+		return &params.Error{
+			Code:    params.CodeMigrationFinished,
+			Message: fmt.Sprintf("migration already finished in phase %s", current),
+		}
+	}
+	if err := s.setPhaseLocked(migration.ABORT.String()); err != nil {
+		return err
+	}
+	s.statusMessage = "aborted: " + reason
+	return nil
+}
+
+func (s *Server) newWatcherLocked(facade, source string) string {
+	s.lastWatcherId++
+	id := strconv.Itoa(s.lastWatcherId)
+	s.watchers[id] = &fakeWatcher{
+		facade:  facade,
+		source:  source,
+		changes: make(chan struct{}, 1),
+		stopped: make(chan struct{}),
+	}
+	return id
+}
+
+func (s *Server) callWatcher(facade, id, request string, result interface{}) error {
+	s.mu.Lock()
+	w, ok := s.watchers[id]
+	if ok && w.facade != facade {
+		ok = false
+	}
+	if ok && request == "Stop" {
+		delete(s.watchers, id)
+	}
+	s.mu.Unlock()
+	if !ok {
+		return &params.Error{
+			Code:    params.CodeNotFound,
+			Message: fmt.Sprintf("unknown watcher id %q", id),
+		}
+	}
+
+	switch request {
+	case "Stop":
+		// The watcher was removed above, so this happens only once.
+		close(w.stopped)
+		return nil
+	case "Next":
+		select {
+		case <-w.changes:
+		case <-w.stopped:
+			return &params.Error{
+				Code:    params.CodeStopped,
+				Message: "watcher was stopped",
+			}
+		}
+		if facade == notifyWatcherFacade {
+			return nil
+		}
+		return respond(result, s.watcherStatus())
+	}
+	return &params.Error{
+		Code:    params.CodeNotImplemented,
+		Message: fmt.Sprintf("unknown method %q", request),
+	}
+}
+
+// watcherStatus returns the status delivered by migration status
+// watchers, as derived from the server's migration status.
+func (s *Server) watcherStatus() params.MigrationStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var attempt int
+	if i := strings.LastIndex(s.status.MigrationId, ":"); i >= 0 {
+		attempt, _ = strconv.Atoi(s.status.MigrationId[i+1:])
+	}
+	return params.MigrationStatus{
+		MigrationId:     s.status.MigrationId,
+		Attempt:         attempt,
+		Phase:           s.status.Phase,
+		ExternalControl: s.status.Spec.ExternalControl,
+		TargetAPIAddrs:  s.status.Spec.TargetInfo.Addrs,
+		TargetCACert:    s.status.Spec.TargetInfo.CACert,
+	}
+}
+
+// respond copies value into result as the API's JSON encoding would,
+// so that the client sees exactly what it would over the wire.
+func respond(result, value interface{}) error {
+	if result == nil {
+		return nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+// fakeWatcher holds the server side of a watcher. Changes are
+// coalesced: any number of notifications made while no Next call is
+// waiting are delivered as a single change.
+type fakeWatcher struct {
+	facade  string
+	source  string
+	changes chan struct{}
+	stopped chan struct{}
+}
+
+func (w *fakeWatcher) notify() {
+	select {
+	case w.changes <- struct{}{}:
+	default:
+	}
+}
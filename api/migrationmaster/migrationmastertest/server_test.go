@@ -0,0 +1,161 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmastertest_test
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/api/migrationmaster/migrationmastertest"
+	"github.com/juju/juju/apiserver/params"
+	"github.com/juju/juju/core/migration"
+	coretesting "github.com/juju/juju/testing"
+	"github.com/juju/juju/watcher"
+	"github.com/juju/juju/watcher/watchertest"
+	"github.com/juju/juju/worker/workertest"
+)
+
+type ServerSuite struct {
+	jujutesting.IsolationSuite
+
+	server *migrationmastertest.Server
+}
+
+var _ = gc.Suite(&ServerSuite{})
+
+func (s *ServerSuite) SetUpTest(c *gc.C) {
+	s.IsolationSuite.SetUpTest(c)
+	s.server = migrationmastertest.NewServer(params.MasterMigrationStatus{
+		Spec: params.MigrationSpec{
+			ModelTag: coretesting.ModelTag.String(),
+			TargetInfo: params.MigrationTargetInfo{
+				ControllerTag: coretesting.ControllerTag.String(),
+				Addrs:         []string{"1.2.3.4:5"},
+				CACert:        "cert",
+				AuthTag:       "user-admin",
+				Password:      "secret",
+			},
+		},
+		MigrationId:      coretesting.ModelTag.Id() + ":2",
+		Phase:            "QUIESCE",
+		PhaseChangedTime: time.Date(2016, 6, 22, 16, 42, 44, 0, time.UTC),
+	})
+}
+
+func (s *ServerSuite) TestMigrationStatus(c *gc.C) {
+	client := s.server.NewClient()
+	status, err := client.MigrationStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(status.MigrationId, gc.Equals, coretesting.ModelTag.Id()+":2")
+	c.Check(status.ModelUUID, gc.Equals, coretesting.ModelTag.Id())
+	c.Check(status.Phase, gc.Equals, migration.QUIESCE)
+	c.Check(status.TargetInfo.Addrs, jc.DeepEquals, []string{"1.2.3.4:5"})
+	s.server.CheckCallNames(c, "MigrationMaster.MigrationStatus")
+}
+
+func (s *ServerSuite) TestSetPhase(c *gc.C) {
+	client := s.server.NewClient()
+	err := client.SetPhase(migration.IMPORT)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.server.Status().Phase, gc.Equals, "IMPORT")
+	s.server.CheckCall(c, 0, "MigrationMaster.SetPhase", params.SetMigrationPhaseArgs{Phase: "IMPORT"})
+}
+
+func (s *ServerSuite) TestSetPhaseIllegal(c *gc.C) {
+	client := s.server.NewClient()
+	err := client.SetPhase(migration.SUCCESS)
+	c.Check(err, gc.ErrorMatches, "failed to set phase: illegal phase change: QUIESCE -> SUCCESS")
+	c.Check(err, jc.Satisfies, params.IsCodeIllegalPhaseChange)
+	c.Check(s.server.Status().Phase, gc.Equals, "QUIESCE")
+}
+
+func (s *ServerSuite) TestAbort(c *gc.C) {
+	client := s.server.NewClient()
+	err := client.Abort("target unreachable")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.server.Status().Phase, gc.Equals, "ABORT")
+	c.Check(s.server.StatusMessage(), gc.Equals, "aborted: target unreachable")
+}
+
+func (s *ServerSuite) TestAbortFinished(c *gc.C) {
+	status := s.server.Status()
+	status.Phase = "DONE"
+	s.server.SetStatus(status)
+
+	client := s.server.NewClient()
+	err := client.Abort("too late")
+	c.Check(err, gc.ErrorMatches, "migration already finished in phase DONE")
+	c.Check(err, jc.Satisfies, params.IsCodeMigrationFinished)
+}
+
+func (s *ServerSuite) TestCallError(c *gc.C) {
+	s.server.SetErrors(nil, errors.New("boom"))
+	client := s.server.NewClient()
+	c.Check(client.Prechecks(), jc.ErrorIsNil)
+	c.Check(client.Reap(), gc.ErrorMatches, "boom")
+	s.server.CheckCallNames(c, "MigrationMaster.Prechecks", "MigrationMaster.Reap")
+}
+
+func (s *ServerSuite) TestWatch(c *gc.C) {
+	client := s.server.NewClient()
+	w, err := client.Watch()
+	c.Assert(err, jc.ErrorIsNil)
+	defer workertest.CleanKill(c, w)
+
+	wc := watchertest.NewNotifyWatcherC(c, w, nil)
+	wc.AssertOneChange()
+	s.server.NotifyMigration()
+	wc.AssertOneChange()
+	s.server.NotifyMinionReports()
+	wc.AssertNoChange()
+}
+
+func (s *ServerSuite) TestWatchMinionReports(c *gc.C) {
+	client := s.server.NewClient()
+	w, err := client.WatchMinionReports()
+	c.Assert(err, jc.ErrorIsNil)
+	defer workertest.CleanKill(c, w)
+
+	wc := watchertest.NewNotifyWatcherC(c, w, nil)
+	wc.AssertOneChange()
+	s.server.NotifyMinionReports()
+	wc.AssertOneChange()
+}
+
+func (s *ServerSuite) TestWatchMigrationStatus(c *gc.C) {
+	client := s.server.NewClient()
+	w, err := client.WatchMigrationStatus()
+	c.Assert(err, jc.ErrorIsNil)
+	defer workertest.CleanKill(c, w)
+
+	status := s.nextStatus(c, w)
+	c.Check(status, jc.DeepEquals, watcher.MigrationStatus{
+		MigrationId:    coretesting.ModelTag.Id() + ":2",
+		Attempt:        2,
+		Phase:          migration.QUIESCE,
+		TargetAPIAddrs: []string{"1.2.3.4:5"},
+		TargetCACert:   "cert",
+	})
+
+	for _, phase := range []migration.Phase{migration.IMPORT, migration.VALIDATION} {
+		err := client.SetPhase(phase)
+		c.Assert(err, jc.ErrorIsNil)
+		c.Check(s.nextStatus(c, w).Phase, gc.Equals, phase)
+	}
+}
+
+func (s *ServerSuite) nextStatus(c *gc.C, w watcher.MigrationStatusWatcher) watcher.MigrationStatus {
+	select {
+	case status, ok := <-w.Changes():
+		c.Assert(ok, jc.IsTrue)
+		return status
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("timed out waiting for migration status")
+	}
+	panic("unreachable")
+}
@@ -0,0 +1,143 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/juju/errors"
+)
+
+// MaxResourceResumes is the number of times reading a resource opened
+// with OpenResource is resumed after the connection fails.
+var MaxResourceResumes = 3
+
+// OpenResource streams the artifact at the given URI, relative to the
+// source controller's API, such as one of the tools URIs returned by
+// Export. The artifact is read lazily. If the connection fails part
+// way through, reading resumes where it left off with an HTTP Range
+// request, up to MaxResourceResumes times.
+func (c *Client) OpenResource(uri string) (io.ReadCloser, error) {
+	httpClient, err := c.caller.RawAPICaller().HTTPClient()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	r := &resumableReader{
+		doer:    httpClient,
+		uri:     uri,
+		resumes: MaxResourceResumes,
+	}
+	body, err := r.open()
+	if err != nil {
+		return nil, errors.Annotatef(err, "opening %q", uri)
+	}
+	r.body = body
+	return r, nil
+}
+
+// httpDoer is the part of *httprequest.Client used by resumableReader.
+type httpDoer interface {
+	Do(req *http.Request, body io.ReadSeeker, resp interface{}) error
+}
+
+// resumableReader reads an HTTP resource, reopening it from the
+// current offset when a read fails.
+type resumableReader struct {
+	doer    httpDoer
+	uri     string
+	offset  int64
+	resumes int
+
+	// body is nil once resuming has failed, in which case err
+	// holds the error returned by all further reads.
+	body io.ReadCloser
+	err  error
+}
+
+// open requests the resource from the current offset, returning its
+// body positioned at that offset.
+func (r *resumableReader) open() (io.ReadCloser, error) {
+	req, err := http.NewRequest("GET", r.uri, nil)
+	if err != nil {
+		return nil, errors.Annotate(err, "cannot create HTTP request")
+	}
+	if r.offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", r.offset))
+	}
+	var resp *http.Response
+	if err := r.doer.Do(req, nil, &resp); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if r.offset == 0 {
+		return resp.Body, nil
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		start, err := contentRangeStart(resp.Header.Get("Content-Range"))
+		if err != nil {
+			resp.Body.Close()
+			return nil, errors.Trace(err)
+		}
+		if start != r.offset {
+			resp.Body.Close()
+			return nil, errors.Errorf("server resumed at byte %d, expected %d", start, r.offset)
+		}
+		return resp.Body, nil
+	}
+	// The server ignored the Range header and sent the
+	// whole resource, so skip what has already been read.
+	if _, err := io.CopyN(ioutil.Discard, resp.Body, r.offset); err != nil {
+		resp.Body.Close()
+		return nil, errors.Annotate(err, "skipping to resume offset")
+	}
+	return resp.Body, nil
+}
+
+// contentRangeStart returns the first byte position of a
+// "bytes first-last/length" Content-Range header value.
+func contentRangeStart(value string) (int64, error) {
+	var start, end int64
+	var length string
+	if _, err := fmt.Sscanf(value, "bytes %d-%d/%s", &start, &end, &length); err != nil {
+		return 0, errors.NotValidf("Content-Range %q", value)
+	}
+	return start, nil
+}
+
+// Read is part of the io.Reader interface.
+func (r *resumableReader) Read(p []byte) (int, error) {
+	if r.body == nil {
+		return 0, r.err
+	}
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || err == io.EOF || r.resumes == 0 {
+			return n, err
+		}
+		r.resumes--
+		r.body.Close()
+		r.body = nil
+		body, openErr := r.open()
+		if openErr != nil {
+			r.err = errors.Annotatef(err, "resuming at byte %d failed (%v)", r.offset, openErr)
+			return n, r.err
+		}
+		r.body = body
+		if n > 0 {
+			return n, nil
+		}
+	}
+}
+
+// Close is part of the io.Closer interface.
+func (r *resumableReader) Close() error {
+	if r.body == nil {
+		// The body was closed when resuming failed.
+		return nil
+	}
+	return r.body.Close()
+}
@@ -0,0 +1,159 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migrationmaster_test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/juju/httprequest"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	apitesting "github.com/juju/juju/api/base/testing"
+	"github.com/juju/juju/api/migrationmaster"
+)
+
+type ResourceSuite struct {
+	jujutesting.IsolationSuite
+}
+
+var _ = gc.Suite(&ResourceSuite{})
+
+const resourceContent = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// flakyResourceHandler serves resourceContent in chunks. Requests
+// without a Range header have their connection dropped after
+// failAfter bytes. Range requests fail if failResume is set, and
+// are answered from rangeSkew bytes past the requested offset.
+type flakyResourceHandler struct {
+	failAfter  int
+	failResume bool
+	rangeSkew  int
+	ranges     []string
+}
+
+func (h *flakyResourceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.ranges = append(h.ranges, r.Header.Get("Range"))
+	var offset int
+	if rng := r.Header.Get("Range"); rng != "" {
+		if _, err := fmt.Sscanf(rng, "bytes=%d-", &offset); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if h.failResume {
+			http.Error(w, "resume failed", http.StatusInternalServerError)
+			return
+		}
+		offset += h.rangeSkew
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", offset, len(resourceContent)-1, len(resourceContent)))
+		w.WriteHeader(http.StatusPartialContent)
+	} else if h.failAfter > 0 {
+		fmt.Fprint(w, resourceContent[:h.failAfter])
+		w.(http.Flusher).Flush()
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			conn.Close()
+		}
+		return
+	}
+	rest := resourceContent[offset:]
+	for _, chunk := range []string{rest[:len(rest)/2], rest[len(rest)/2:]} {
+		fmt.Fprint(w, chunk)
+		w.(http.Flusher).Flush()
+	}
+}
+
+// httpAPICaller is an APICallerFunc whose HTTP client talks to a
+// test server.
+type httpAPICaller struct {
+	apitesting.APICallerFunc
+	client *httprequest.Client
+}
+
+func (c httpAPICaller) HTTPClient() (*httprequest.Client, error) {
+	return c.client, nil
+}
+
+func (s *ResourceSuite) newClient(c *gc.C, handler http.Handler) *migrationmaster.Client {
+	server := httptest.NewServer(handler)
+	s.AddCleanup(func(*gc.C) { server.Close() })
+	caller := httpAPICaller{
+		APICallerFunc: func(string, int, string, string, interface{}, interface{}) error {
+			c.Fatalf("unexpected API call")
+			return nil
+		},
+		client: &httprequest.Client{BaseURL: server.URL},
+	}
+	return migrationmaster.NewClient(caller, nil)
+}
+
+func (s *ResourceSuite) TestOpenResource(c *gc.C) {
+	handler := &flakyResourceHandler{}
+	client := s.newClient(c, handler)
+	r, err := client.OpenResource("/tools/2.0.0-xenial-amd64")
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, resourceContent)
+	c.Assert(handler.ranges, jc.DeepEquals, []string{""})
+}
+
+func (s *ResourceSuite) TestOpenResourceResumes(c *gc.C) {
+	handler := &flakyResourceHandler{failAfter: 10}
+	client := s.newClient(c, handler)
+	r, err := client.OpenResource("/tools/2.0.0-xenial-amd64")
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), gc.Equals, resourceContent)
+	c.Assert(handler.ranges, jc.DeepEquals, []string{"", "bytes=10-"})
+}
+
+func (s *ResourceSuite) TestOpenResourceNoResumes(c *gc.C) {
+	s.PatchValue(&migrationmaster.MaxResourceResumes, 0)
+	handler := &flakyResourceHandler{failAfter: 10}
+	client := s.newClient(c, handler)
+	r, err := client.OpenResource("/tools/2.0.0-xenial-amd64")
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, gc.NotNil)
+	c.Assert(strings.HasPrefix(resourceContent, string(data)), jc.IsTrue)
+	c.Assert(handler.ranges, jc.DeepEquals, []string{""})
+}
+
+func (s *ResourceSuite) TestOpenResourceResumeFails(c *gc.C) {
+	handler := &flakyResourceHandler{failAfter: 10, failResume: true}
+	client := s.newClient(c, handler)
+	r, err := client.OpenResource("/tools/2.0.0-xenial-amd64")
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, gc.ErrorMatches, "resuming at byte 10 failed .*")
+	c.Assert(string(data), gc.Equals, resourceContent[:10])
+	c.Assert(handler.ranges, jc.DeepEquals, []string{"", "bytes=10-"})
+
+	// Later reads keep failing, and Close does not close the
+	// original body a second time.
+	_, err = r.Read(make([]byte, 1))
+	c.Assert(err, gc.ErrorMatches, "resuming at byte 10 failed .*")
+	c.Assert(r.Close(), jc.ErrorIsNil)
+}
+
+func (s *ResourceSuite) TestOpenResourceResumeWrongOffset(c *gc.C) {
+	handler := &flakyResourceHandler{failAfter: 10, rangeSkew: 5}
+	client := s.newClient(c, handler)
+	r, err := client.OpenResource("/tools/2.0.0-xenial-amd64")
+	c.Assert(err, jc.ErrorIsNil)
+	defer r.Close()
+	data, err := ioutil.ReadAll(r)
+	c.Assert(err, gc.ErrorMatches, `resuming at byte 10 failed \(server resumed at byte 15, expected 10\): .*`)
+	c.Assert(string(data), gc.Equals, resourceContent[:10])
+}
@@ -55,5 +55,13 @@ func (*AgentAuthenticator) Authenticate(entityFinder EntityFinder, tag names.Tag
 		}
 	}
 
+	// Now that the agent has logged in, move its stored password
+	// hash to the current scheme if it isn't there already.
+	if upgrader, ok := authenticator.(state.PasswordHashUpgrader); ok {
+		if err := upgrader.UpgradePasswordHash(req.Credentials); err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
 	return entity, nil
 }
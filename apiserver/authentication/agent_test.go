@@ -4,9 +4,11 @@
 package authentication_test
 
 import (
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/apiserver/authentication"
 	"github.com/juju/juju/apiserver/params"
@@ -146,3 +148,67 @@ func (s *agentAuthenticatorSuite) TestInvalidLogins(c *gc.C) {
 		c.Assert(entity, gc.IsNil)
 	}
 }
+
+func (s *agentAuthenticatorSuite) TestLoginUpgradesPasswordHash(c *gc.C) {
+	entity := &upgradingEntity{tag: names.NewUnitTag("wordpress/1"), password: "secret"}
+	var authenticator authentication.AgentAuthenticator
+	_, err := authenticator.Authenticate(entityFinder{entity}, entity.tag, params.LoginRequest{
+		Credentials: "secret",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(entity.upgraded, jc.DeepEquals, []string{"secret"})
+}
+
+func (s *agentAuthenticatorSuite) TestLoginUpgradePasswordHashError(c *gc.C) {
+	entity := &upgradingEntity{
+		tag:        names.NewUnitTag("wordpress/1"),
+		password:   "secret",
+		upgradeErr: errors.New("boom"),
+	}
+	var authenticator authentication.AgentAuthenticator
+	_, err := authenticator.Authenticate(entityFinder{entity}, entity.tag, params.LoginRequest{
+		Credentials: "secret",
+	})
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *agentAuthenticatorSuite) TestInvalidLoginDoesNotUpgradePasswordHash(c *gc.C) {
+	entity := &upgradingEntity{tag: names.NewUnitTag("wordpress/1"), password: "secret"}
+	var authenticator authentication.AgentAuthenticator
+	_, err := authenticator.Authenticate(entityFinder{entity}, entity.tag, params.LoginRequest{
+		Credentials: "wrong",
+	})
+	c.Assert(err, gc.ErrorMatches, "invalid entity name or password")
+	c.Assert(entity.upgraded, gc.HasLen, 0)
+}
+
+type entityFinder struct {
+	entity state.Entity
+}
+
+func (f entityFinder) FindEntity(tag names.Tag) (state.Entity, error) {
+	return f.entity, nil
+}
+
+// upgradingEntity is an agent entity that records the passwords
+// its stored hash is upgraded with.
+type upgradingEntity struct {
+	state.Authenticator
+	tag        names.Tag
+	password   string
+	upgraded   []string
+	upgradeErr error
+}
+
+func (e *upgradingEntity) Tag() names.Tag {
+	return e.tag
+}
+
+func (e *upgradingEntity) PasswordValid(password string) bool {
+	return password == e.password
+}
+
+func (e *upgradingEntity) UpgradePasswordHash(password string) error {
+	e.upgraded = append(e.upgraded, password)
+	return e.upgradeErr
+}
@@ -233,6 +233,8 @@ func ServerError(err error) *params.Error {
 		code = params.CodeBadRequest
 	case errors.IsMethodNotAllowed(err):
 		code = params.CodeMethodNotAllowed
+	case state.IsIllegalPhaseChangeError(err):
+		code = params.CodeIllegalPhaseChange
 	default:
 		if err, ok := err.(*DischargeRequiredError); ok {
 			code = params.CodeDischargeRequired
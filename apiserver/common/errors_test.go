@@ -18,6 +18,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/core/leadership"
 	"github.com/juju/juju/core/lease"
+	"github.com/juju/juju/core/migration"
 	"github.com/juju/juju/state"
 	"github.com/juju/juju/testing"
 )
@@ -163,6 +164,11 @@ var errorTransformTests = []struct {
 	code:       params.CodeMethodNotAllowed,
 	status:     http.StatusMethodNotAllowed,
 	helperFunc: params.IsMethodNotAllowed,
+}, {
+	err:        &state.IllegalPhaseChangeError{From: migration.QUIESCE, To: migration.SUCCESS},
+	code:       params.CodeIllegalPhaseChange,
+	status:     http.StatusInternalServerError,
+	helperFunc: params.IsCodeIllegalPhaseChange,
 }, {
 	err:    stderrors.New("an error"),
 	status: http.StatusInternalServerError,
@@ -239,7 +245,8 @@ func (s *errorsSuite) TestErrorTransform(c *gc.C) {
 			params.CodeMachineHasAttachedStorage,
 			params.CodeDischargeRequired,
 			params.CodeModelNotFound,
-			params.CodeRetry:
+			params.CodeRetry,
+			params.CodeIllegalPhaseChange:
 			continue
 		case params.CodeOperationBlocked:
 			// ServerError doesn't actually have a case for this code.
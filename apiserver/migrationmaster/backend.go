@@ -15,6 +15,7 @@ import (
 // migrationmaster facade.
 type Backend interface {
 	WatchForMigration() state.NotifyWatcher
+	WatchMigrationStatus() state.NotifyWatcher
 	LatestMigration() (state.ModelMigration, error)
 	ModelUUID() string
 	ModelName() (string, error)
@@ -5,12 +5,14 @@ package migrationmaster
 
 import (
 	"encoding/json"
+	"fmt"
 
 	"github.com/juju/errors"
 	"github.com/juju/utils"
 	"github.com/juju/utils/set"
 	"github.com/juju/version"
 	"gopkg.in/juju/names.v2"
+	"gopkg.in/macaroon.v1"
 
 	"github.com/juju/juju/apiserver/common"
 	"github.com/juju/juju/apiserver/facade"
@@ -22,7 +24,7 @@ import (
 )
 
 func init() {
-	common.RegisterStandardFacade("MigrationMaster", 1, newAPIForRegistration)
+	common.RegisterStandardFacade("MigrationMaster", 2, newAPIForRegistration)
 }
 
 // API implements the API required for the model migration
@@ -68,6 +70,17 @@ func (api *API) Watch() params.NotifyWatchResult {
 	}
 }
 
+// WatchMigrationStatus starts watching for status changes of the
+// latest migration for the model associated with the API connection.
+// The returned id should be used with the MigrationStatusWatcher
+// facade to receive the status with each event.
+func (api *API) WatchMigrationStatus() params.NotifyWatchResult {
+	w := api.backend.WatchMigrationStatus()
+	return params.NotifyWatchResult{
+		NotifyWatcherId: api.resources.Register(w),
+	}
+}
+
 // MigrationStatus returns the details and progress of the latest
 // model migration.
 func (api *API) MigrationStatus() (params.MasterMigrationStatus, error) {
@@ -154,12 +167,83 @@ func (api *API) SetPhase(args params.SetMigrationPhaseArgs) error {
 	return errors.Annotate(err, "failed to set phase")
 }
 
+// Abort aborts the latest migration for the model associated with
+// the API connection, recording the given reason in the migration's
+// status message. If the migration has already finished, an error
+// with code params.CodeMigrationFinished is returned.
+func (api *API) Abort(args params.AbortMigrationArgs) error {
+	if args.Reason == "" {
+		return errors.NotValidf("empty abort reason")
+	}
+	mig, err := api.backend.LatestMigration()
+	if err != nil {
+		return errors.Annotate(err, "could not get migration")
+	}
+	phase, err := mig.Phase()
+	if err != nil {
+		return errors.Annotate(err, "could not get phase")
+	}
+	if phase.IsTerminal() {
+		return &params.Error{
+			Code:    params.CodeMigrationFinished,
+			Message: fmt.Sprintf("migration already finished in phase %s", phase),
+		}
+	}
+	err = mig.SetPhaseWithStatusMessage(coremigration.ABORT, "aborted: "+args.Reason)
+	return errors.Annotate(err, "failed to set phase")
+}
+
 // Prechecks performs pre-migration checks on the model and
 // (source) controller.
 func (api *API) Prechecks() error {
 	return migration.SourcePrecheck(api.precheckBackend)
 }
 
+// Validate reports what migrating the model associated with the API
+// connection to the given target controller would involve, including
+// the charm and tools artifacts that would be transferred, without
+// changing the state of the model or starting a migration. Source
+// prechecks that fail are reported as warnings.
+//
+// The target controller is not contacted, so the target information
+// is only checked for being well formed; whether the target can
+// actually accept the model is not checked.
+func (api *API) Validate(args params.ValidateMigrationArgs) (params.MigrationValidationReport, error) {
+	var report params.MigrationValidationReport
+
+	target, err := parseTargetInfo(args.TargetInfo)
+	if err != nil {
+		return report, errors.Trace(err)
+	}
+	if err := target.Validate(); err != nil {
+		return report, errors.Trace(err)
+	}
+
+	model, err := api.backend.Export()
+	if err != nil {
+		return report, errors.Annotate(err, "exporting model")
+	}
+	bytes, err := description.Serialize(model)
+	if err != nil {
+		return report, errors.Annotate(err, "serializing model")
+	}
+	report.TransferSize = int64(len(bytes))
+	for _, machine := range model.Machines() {
+		report.MachineCount += countMachines(machine)
+	}
+	for _, application := range model.Applications() {
+		report.UnitCount += len(application.Units())
+	}
+	report.Charms = getUsedCharms(model)
+	report.CharmCount = len(report.Charms)
+	report.Tools = getUsedTools(model)
+
+	if err := migration.SourcePrecheck(api.precheckBackend); err != nil {
+		report.Warnings = append(report.Warnings, fmt.Sprintf("prechecks failed: %v", err))
+	}
+	return report, nil
+}
+
 // SetStatusMessage sets a human readable status message containing
 // information about the migration's progress. This will be shown in
 // status output shown to the end user.
@@ -268,6 +352,40 @@ func (api *API) MinionReports() (params.MinionReports, error) {
 	return out, nil
 }
 
+func parseTargetInfo(info params.MigrationTargetInfo) (coremigration.TargetInfo, error) {
+	var empty coremigration.TargetInfo
+	controllerTag, err := names.ParseControllerTag(info.ControllerTag)
+	if err != nil {
+		return empty, errors.Annotate(err, "controller tag")
+	}
+	authTag, err := names.ParseUserTag(info.AuthTag)
+	if err != nil {
+		return empty, errors.Annotate(err, "auth tag")
+	}
+	var macs []macaroon.Slice
+	if info.Macaroons != "" {
+		if err := json.Unmarshal([]byte(info.Macaroons), &macs); err != nil {
+			return empty, errors.Annotate(err, "invalid macaroons")
+		}
+	}
+	return coremigration.TargetInfo{
+		ControllerTag: controllerTag,
+		Addrs:         info.Addrs,
+		CACert:        info.CACert,
+		AuthTag:       authTag,
+		Password:      info.Password,
+		Macaroons:     macs,
+	}, nil
+}
+
+func countMachines(machine description.Machine) int {
+	count := 1
+	for _, container := range machine.Containers() {
+		count += countMachines(container)
+	}
+	return count
+}
+
 func getUsedCharms(model description.Model) []string {
 	result := set.NewStrings()
 	for _, application := range model.Applications() {
@@ -88,6 +88,25 @@ func (s *Suite) TestWatch(c *gc.C) {
 	}
 }
 
+func (s *Suite) TestWatchMigrationStatus(c *gc.C) {
+	api := s.mustMakeAPI(c)
+
+	result := api.WatchMigrationStatus()
+	c.Assert(result.Error, gc.IsNil)
+
+	resource := s.resources.Get(result.NotifyWatcherId)
+	watcher, _ := resource.(state.NotifyWatcher)
+	c.Assert(watcher, gc.NotNil)
+
+	// The initial event is left for the MigrationStatusWatcher
+	// facade to deliver.
+	select {
+	case <-watcher.Changes():
+	case <-time.After(coretesting.LongWait):
+		c.Fatalf("initial event not sent")
+	}
+}
+
 func (s *Suite) TestMigrationStatus(c *gc.C) {
 	var expectedMacaroons = `
 [[{"caveats":[],"location":"location","identifier":"id","signature":"a9802bf274262733d6283a69c62805b5668dbf475bcd7edc25a962833f7c2cba"}]]`[1:]
@@ -163,6 +182,43 @@ func (s *Suite) TestSetPhaseError(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "failed to set phase: blam")
 }
 
+func (s *Suite) TestAbort(c *gc.C) {
+	api := s.mustMakeAPI(c)
+
+	err := api.Abort(params.AbortMigrationArgs{Reason: "target unreachable"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(s.backend.migration.phaseSet, gc.Equals, coremigration.ABORT)
+	c.Check(s.backend.migration.messageSet, gc.Equals, "aborted: target unreachable")
+}
+
+func (s *Suite) TestAbortNoReason(c *gc.C) {
+	api := s.mustMakeAPI(c)
+
+	err := api.Abort(params.AbortMigrationArgs{})
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Check(s.backend.migration.phaseSet, gc.Equals, coremigration.UNKNOWN)
+}
+
+func (s *Suite) TestAbortFinished(c *gc.C) {
+	s.backend.migration.phase = coremigration.DONE
+	api := s.mustMakeAPI(c)
+
+	err := api.Abort(params.AbortMigrationArgs{Reason: "too late"})
+	c.Assert(err, jc.Satisfies, params.IsCodeMigrationFinished)
+	c.Assert(err, gc.ErrorMatches, "migration already finished in phase DONE")
+	c.Check(s.backend.migration.phaseSet, gc.Equals, coremigration.UNKNOWN)
+	c.Check(s.backend.migration.messageSet, gc.Equals, "")
+}
+
+func (s *Suite) TestAbortSetPhaseError(c *gc.C) {
+	s.backend.migration.setPhaseErr = errors.New("blam")
+	api := s.mustMakeAPI(c)
+
+	err := api.Abort(params.AbortMigrationArgs{Reason: "target unreachable"})
+	c.Assert(err, gc.ErrorMatches, "failed to set phase: blam")
+	c.Check(s.backend.migration.messageSet, gc.Equals, "")
+}
+
 func (s *Suite) TestSetStatusMessage(c *gc.C) {
 	api := s.mustMakeAPI(c)
 
@@ -218,6 +274,73 @@ func (s *Suite) TestExport(c *gc.C) {
 	})
 }
 
+func (s *Suite) TestValidate(c *gc.C) {
+	const tools = "2.0.0-xenial-amd64"
+	toolsArgs := description.AgentToolsArgs{
+		Version: version.MustParseBinary(tools),
+	}
+	s.model.AddApplication(description.ApplicationArgs{
+		Tag:      names.NewApplicationTag("foo"),
+		CharmURL: "cs:foo-0",
+	}).AddUnit(description.UnitArgs{
+		Tag: names.NewUnitTag("foo/0"),
+	}).SetTools(toolsArgs)
+	m := s.model.AddMachine(description.MachineArgs{Id: names.NewMachineTag("0")})
+	m.SetTools(toolsArgs)
+	m.AddContainer(description.MachineArgs{Id: names.NewMachineTag("0/lxd/0")}).SetTools(toolsArgs)
+	s.model.AddMachine(description.MachineArgs{Id: names.NewMachineTag("1")}).SetTools(toolsArgs)
+	api := s.mustMakeAPI(c)
+
+	report, err := api.Validate(params.ValidateMigrationArgs{
+		TargetInfo: s.validTargetInfo(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	bytes, err := description.Serialize(s.model)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(report, jc.DeepEquals, params.MigrationValidationReport{
+		TransferSize: int64(len(bytes)),
+		MachineCount: 3,
+		UnitCount:    1,
+		CharmCount:   1,
+		Charms:       []string{"cs:foo-0"},
+		Tools: []params.SerializedModelTools{
+			{tools, "/tools/" + tools},
+		},
+		Warnings: []string{"prechecks failed: retrieving model: boom"},
+	})
+	// Validate must not touch the migration itself.
+	s.stub.CheckCallNames(c, "Export")
+}
+
+func (s *Suite) TestValidateBadTargetInfo(c *gc.C) {
+	api := s.mustMakeAPI(c)
+	target := s.validTargetInfo()
+	target.Addrs = nil
+
+	_, err := api.Validate(params.ValidateMigrationArgs{TargetInfo: target})
+	c.Assert(err, gc.ErrorMatches, "empty Addrs not valid")
+	s.stub.CheckNoCalls(c)
+}
+
+func (s *Suite) TestValidateBadControllerTag(c *gc.C) {
+	api := s.mustMakeAPI(c)
+	target := s.validTargetInfo()
+	target.ControllerTag = "machine-0"
+
+	_, err := api.Validate(params.ValidateMigrationArgs{TargetInfo: target})
+	c.Assert(err, gc.ErrorMatches, `controller tag: "machine-0" is not a valid controller tag`)
+}
+
+func (s *Suite) validTargetInfo() params.MigrationTargetInfo {
+	return params.MigrationTargetInfo{
+		ControllerTag: names.NewControllerTag(controllerUUID).String(),
+		Addrs:         []string{"1.1.1.1:1", "2.2.2.2:2"},
+		CACert:        "trust me",
+		AuthTag:       names.NewUserTag("admin").String(),
+		Password:      "secret",
+	}
+}
+
 func (s *Suite) TestReap(c *gc.C) {
 	api := s.mustMakeAPI(c)
 
@@ -330,6 +453,11 @@ func (b *stubBackend) WatchForMigration() state.NotifyWatcher {
 	return apiservertesting.NewFakeNotifyWatcher()
 }
 
+func (b *stubBackend) WatchMigrationStatus() state.NotifyWatcher {
+	b.stub.AddCall("WatchMigrationStatus")
+	return apiservertesting.NewFakeNotifyWatcher()
+}
+
 func (b *stubBackend) LatestMigration() (state.ModelMigration, error) {
 	b.stub.AddCall("LatestMigration")
 	if b.getErr != nil {
@@ -368,6 +496,7 @@ type stubMigration struct {
 	state.ModelMigration
 
 	stub            *testing.Stub
+	phase           coremigration.Phase
 	setPhaseErr     error
 	phaseSet        coremigration.Phase
 	setMessageErr   error
@@ -381,6 +510,9 @@ func (m *stubMigration) Id() string {
 }
 
 func (m *stubMigration) Phase() (coremigration.Phase, error) {
+	if m.phase != coremigration.UNKNOWN {
+		return m.phase, nil
+	}
 	return coremigration.IMPORT, nil
 }
 
@@ -423,6 +555,15 @@ func (m *stubMigration) SetPhase(phase coremigration.Phase) error {
 	return nil
 }
 
+func (m *stubMigration) SetPhaseWithStatusMessage(phase coremigration.Phase, message string) error {
+	if m.setPhaseErr != nil {
+		return m.setPhaseErr
+	}
+	m.phaseSet = phase
+	m.messageSet = message
+	return nil
+}
+
 func (m *stubMigration) SetStatusMessage(message string) error {
 	if m.setMessageErr != nil {
 		return m.setMessageErr
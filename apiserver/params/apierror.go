@@ -90,6 +90,8 @@ const (
 	CodeDischargeRequired         = "macaroon discharge required"
 	CodeRedirect                  = "redirection required"
 	CodeRetry                     = "retry"
+	CodeIllegalPhaseChange        = "illegal phase change"
+	CodeMigrationFinished         = "migration finished"
 )
 
 // ErrCode returns the error code associated with
@@ -235,3 +237,11 @@ func IsMethodNotAllowed(err error) bool {
 func IsRedirect(err error) bool {
 	return ErrCode(err) == CodeRedirect
 }
+
+func IsCodeIllegalPhaseChange(err error) bool {
+	return ErrCode(err) == CodeIllegalPhaseChange
+}
+
+func IsCodeMigrationFinished(err error) bool {
+	return ErrCode(err) == CodeMigrationFinished
+}
@@ -65,6 +65,12 @@ type SetMigrationStatusMessageArgs struct {
 	Message string `json:"message"`
 }
 
+// AbortMigrationArgs provides the reason for aborting a migration
+// to the migrationmaster.Abort API method.
+type AbortMigrationArgs struct {
+	Reason string `json:"reason"`
+}
+
 // SerializedModel wraps a buffer contain a serialised Juju model. It
 // also contains lists of the charms and tools used in the model.
 type SerializedModel struct {
@@ -178,3 +184,22 @@ type MinionReports struct {
 	// failed to complete a given migration phase.
 	Failed []string `json:"failed"`
 }
+
+// ValidateMigrationArgs provides the target controller details to the
+// migrationmaster.Validate API method.
+type ValidateMigrationArgs struct {
+	TargetInfo MigrationTargetInfo `json:"target-info"`
+}
+
+// MigrationValidationReport holds the result of the
+// migrationmaster.Validate API method.
+type MigrationValidationReport struct {
+	TransferSize int64    `json:"transfer-size"`
+	MachineCount int      `json:"machine-count"`
+	UnitCount    int      `json:"unit-count"`
+	CharmCount   int      `json:"charm-count"`
+	Warnings     []string `json:"warnings,omitempty"`
+
+	Charms []string               `json:"charms"`
+	Tools  []SerializedModelTools `json:"tools"`
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/juju/juju/apiserver/params"
 	"github.com/juju/juju/controller"
 	"github.com/juju/juju/core/migration"
+	"github.com/juju/juju/environs"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/permission"
 	"github.com/juju/juju/state"
@@ -530,9 +531,8 @@ var getControllerCACert = func(st migrationBackend) (string, error) {
 		return "", errors.Trace(err)
 	}
 
-	cacert, ok := cfg.CACert()
-	if !ok {
+	if _, ok := cfg.CACert(); !ok {
 		return "", errors.New("missing CA cert for controller model")
 	}
-	return cacert, nil
+	return environs.APICACert(cfg)
 }
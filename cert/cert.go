@@ -38,6 +38,32 @@ func ParseCert(certPEM string) (*x509.Certificate, error) {
 	return nil, errors.New("no certificates found")
 }
 
+// ParseCerts parses all the PEM-formatted X509 certificates in the
+// given bundle, in order. Other PEM blocks are ignored.
+func ParseCerts(certsPEM string) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	certPEMData := []byte(certsPEM)
+	for len(certPEMData) > 0 {
+		var certBlock *pem.Block
+		certBlock, certPEMData = pem.Decode(certPEMData)
+		if certBlock == nil {
+			break
+		}
+		if certBlock.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(certBlock.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no certificates found")
+	}
+	return certs, nil
+}
+
 // ParseCertAndKey parses the given PEM-formatted X509 certificate
 // and RSA private key.
 func ParseCertAndKey(certPEM, keyPEM string) (*x509.Certificate, *rsa.PrivateKey, error) {
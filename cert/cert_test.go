@@ -58,6 +58,22 @@ func (certSuite) TestParseCertificate(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, "no certificates found")
 }
 
+func (certSuite) TestParseCerts(c *gc.C) {
+	expiry := time.Now().UTC().AddDate(1, 0, 0)
+	otherCertPEM, _, err := cert.NewCA("other", "1", expiry)
+	c.Assert(err, jc.ErrorIsNil)
+
+	xcerts, err := cert.ParseCerts(caCertPEM + caKeyPEM + otherCertPEM)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(xcerts, gc.HasLen, 2)
+	c.Check(xcerts[0].Subject.CommonName, gc.Equals, "juju testing")
+	c.Check(xcerts[1].Subject.CommonName, gc.Equals, "juju-generated CA for model \"other\"")
+
+	xcerts, err = cert.ParseCerts(caKeyPEM)
+	c.Check(xcerts, gc.IsNil)
+	c.Assert(err, gc.ErrorMatches, "no certificates found")
+}
+
 func (certSuite) TestParseCertAndKey(c *gc.C) {
 	xcert, key, err := cert.ParseCertAndKey(caCertPEM, caKeyPEM)
 	c.Assert(err, jc.ErrorIsNil)
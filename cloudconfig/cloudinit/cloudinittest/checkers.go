@@ -0,0 +1,70 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package cloudinittest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/juju/utils/set"
+	gc "gopkg.in/check.v1"
+)
+
+// CheckScripts checks the runcmd entry of the parsed cloud-init user
+// data. If match is true, it checks that at least one script matches
+// the given regexp pattern; otherwise it checks that none does.
+func CheckScripts(c *gc.C, userDataMap map[interface{}]interface{}, pattern string, match bool) {
+	scripts0 := userDataMap["runcmd"]
+	if scripts0 == nil {
+		c.Errorf("cloudinit has no entry for runcmd")
+		return
+	}
+	scripts := scripts0.([]interface{})
+	re := regexp.MustCompile(pattern)
+	found := false
+	for _, s0 := range scripts {
+		s := s0.(string)
+		if re.MatchString(s) {
+			found = true
+		}
+	}
+	switch {
+	case match && !found:
+		c.Errorf("script %q not found in %q", pattern, scripts)
+	case !match && found:
+		c.Errorf("script %q found but not expected in %q", pattern, scripts)
+	}
+}
+
+// CheckPackage checks that the parsed cloud-init user data will or
+// won't install the given package, depending on the value of match.
+func CheckPackage(c *gc.C, userDataMap map[interface{}]interface{}, pkg string, match bool) {
+	pkgs0 := userDataMap["packages"]
+	if pkgs0 == nil {
+		if match {
+			c.Errorf("cloudinit has no entry for packages")
+		}
+		return
+	}
+
+	pkgs := pkgs0.([]interface{})
+
+	found := false
+	for _, p0 := range pkgs {
+		p := p0.(string)
+		// p might be a space separate list of packages eg 'foo bar qed' so split them up
+		manyPkgs := set.NewStrings(strings.Split(p, " ")...)
+		hasPkg := manyPkgs.Contains(pkg)
+		if p == pkg || hasPkg {
+			found = true
+			break
+		}
+	}
+	switch {
+	case match && !found:
+		c.Errorf("package %q not found in %v", pkg, pkgs)
+	case !match && found:
+		c.Errorf("%q found but not expected in %v", pkg, pkgs)
+	}
+}
@@ -31,6 +31,11 @@ const (
 	// CACertKey is the key for the controller's CA certificate attribute.
 	CACertKey = "ca-cert"
 
+	// PreviousCACertKey is the key for the attribute holding the
+	// controller's previous CA certificate, which is still accepted
+	// while the CA certificate is being rotated.
+	PreviousCACertKey = "previous-ca-cert"
+
 	// ControllerUUIDKey is the key for the controller UUID attribute.
 	ControllerUUIDKey = "controller-uuid"
 
@@ -66,6 +71,7 @@ var ControllerOnlyConfigAttributes = []string{
 	ApiPort,
 	StatePort,
 	CACertKey,
+	PreviousCACertKey,
 	ControllerUUIDKey,
 	IdentityURL,
 	IdentityPublicKey,
@@ -174,6 +180,17 @@ func (c Config) CACert() (string, bool) {
 	return "", false
 }
 
+// PreviousCACert returns the certificate of the CA that previously
+// signed the controller certificate, in PEM format, and whether the
+// setting is available. It is only set while the CA certificate is
+// being rotated.
+func (c Config) PreviousCACert() (string, bool) {
+	if s, ok := c[PreviousCACertKey].(string); ok && s != "" {
+		return s, true
+	}
+	return "", false
+}
+
 // IdentityURL returns the url of the identity manager.
 func (c Config) IdentityURL() string {
 	return c.asString(IdentityURL)
@@ -230,6 +247,11 @@ func Validate(c Config) error {
 	if _, err := cert.ParseCert(caCert); err != nil {
 		return errors.Annotate(err, "bad CA certificate in configuration")
 	}
+	if previousCACert, ok := c.PreviousCACert(); ok {
+		if _, err := cert.ParseCert(previousCACert); err != nil {
+			return errors.Annotate(err, "bad previous CA certificate in configuration")
+		}
+	}
 
 	if uuid, ok := c[ControllerUUIDKey].(string); ok && !utils.IsValidUUIDString(uuid) {
 		return errors.Errorf("controller-uuid: expected UUID, got string(%q)", uuid)
@@ -251,6 +273,7 @@ var configChecker = schema.FieldMap(schema.Fields{
 	IdentityURL:             schema.String(),
 	IdentityPublicKey:       schema.String(),
 	SetNumaControlPolicyKey: schema.Bool(),
+	PreviousCACertKey:       schema.String(),
 }, schema.Defaults{
 	ApiPort:                 DefaultAPIPort,
 	AuditingEnabled:         DefaultAuditingEnabled,
@@ -258,4 +281,5 @@ var configChecker = schema.FieldMap(schema.Fields{
 	IdentityURL:             schema.Omit,
 	IdentityPublicKey:       schema.Omit,
 	SetNumaControlPolicyKey: DefaultNumaControlPolicy,
+	PreviousCACertKey:       schema.Omit,
 })
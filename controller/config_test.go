@@ -72,3 +72,27 @@ func (s *ConfigSuite) TestGenerateControllerCertAndKey(c *gc.C) {
 		c.Assert(sanIPs, jc.SameContents, test.sanValues)
 	}
 }
+
+func (s *ConfigSuite) TestPreviousCACert(c *gc.C) {
+	cfg, err := controller.NewConfig(testing.ModelTag.Id(), testing.CACert, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	_, ok := cfg.PreviousCACert()
+	c.Assert(ok, jc.IsFalse)
+
+	previousCACert, _, err := cert.NewCA("previous", "1", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, jc.ErrorIsNil)
+	cfg, err = controller.NewConfig(testing.ModelTag.Id(), testing.CACert, map[string]interface{}{
+		controller.PreviousCACertKey: previousCACert,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	caCert, ok := cfg.PreviousCACert()
+	c.Assert(ok, jc.IsTrue)
+	c.Assert(caCert, gc.Equals, previousCACert)
+}
+
+func (s *ConfigSuite) TestPreviousCACertInvalid(c *gc.C) {
+	_, err := controller.NewConfig(testing.ModelTag.Id(), testing.CACert, map[string]interface{}{
+		controller.PreviousCACertKey: "blah",
+	})
+	c.Assert(err, gc.ErrorMatches, "bad previous CA certificate in configuration: no certificates found")
+}
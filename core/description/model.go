@@ -82,6 +82,25 @@ func Deserialize(bytes []byte) (Model, error) {
 	return model, nil
 }
 
+// CheckVersion returns an error if the serialization format version of
+// the given serialized model is missing or not understood by
+// Deserialize. The rest of the model is not validated.
+func CheckVersion(bytes []byte) error {
+	var source map[string]interface{}
+	err := yaml.Unmarshal(bytes, &source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	version, err := getVersion(source)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	if _, ok := modelDeserializationFuncs[version]; !ok {
+		return errors.NotValidf("version %d", version)
+	}
+	return nil
+}
+
 // parseLinkLayerDeviceGlobalKey is used to validate that the parent device
 // referenced by a LinkLayerDevice exists. Copied from state to avoid exporting
 // and will be replaced by device.ParentMachineID() at some point.
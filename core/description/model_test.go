@@ -74,6 +74,20 @@ func (s *ModelSerializationSuite) exportImport(c *gc.C, initial Model) Model {
 	return model
 }
 
+func (s *ModelSerializationSuite) TestCheckVersion(c *gc.C) {
+	bytes, err := Serialize(NewModel(ModelArgs{Owner: names.NewUserTag("magic")}))
+	c.Assert(err, jc.ErrorIsNil)
+	err = CheckVersion(bytes)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = CheckVersion([]byte("version: 999\n"))
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, "version 999 not valid")
+
+	err = CheckVersion([]byte("owner: magic\n"))
+	c.Assert(err, gc.ErrorMatches, "version: expected int, got nothing")
+}
+
 func (s *ModelSerializationSuite) TestParsingYAML(c *gc.C) {
 	args := ModelArgs{
 		Owner: names.NewUserTag("magic"),
@@ -75,3 +75,35 @@ func (i *ModelInfo) Validate() error {
 	}
 	return nil
 }
+
+// ValidationReport describes what migrating a model to a target
+// controller would involve, as determined without starting the
+// migration.
+type ValidationReport struct {
+	// TransferSize holds the size in bytes of the serialized model
+	// that would be transferred to the target controller. The charm
+	// and tools archives sent alongside it are not included.
+	TransferSize int64
+
+	// MachineCount holds the number of machines in the model.
+	MachineCount int
+
+	// UnitCount holds the number of units in the model.
+	UnitCount int
+
+	// CharmCount holds the number of charms used by the model.
+	CharmCount int
+
+	// Charms lists the URLs of the charms that would be transferred
+	// to the target controller.
+	Charms []string
+
+	// Tools lists the tools versions that would be transferred to
+	// the target controller, along with their URIs on the source
+	// controller.
+	Tools map[version.Binary]string // version -> tools URI
+
+	// Warnings holds any problems found that operators should know
+	// about before migrating, such as failed prechecks.
+	Warnings []string
+}
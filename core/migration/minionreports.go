@@ -3,6 +3,8 @@
 
 package migration
 
+import "gopkg.in/juju/names.v2"
+
 // MinionReports returns information about the migration minion
 // reports received so far for a given migration phase.
 type MinionReports struct {
@@ -41,3 +43,22 @@ type MinionReports struct {
 func (r *MinionReports) IsZero() bool {
 	return r.MigrationId == "" && r.Phase == UNKNOWN
 }
+
+// FailedCount returns the number of agents which have failed to
+// complete the migration phase.
+func (r *MinionReports) FailedCount() int {
+	return len(r.FailedMachines) + len(r.FailedUnits)
+}
+
+// FailedTags returns the tags of the agents which have failed to
+// complete the migration phase, machines first.
+func (r *MinionReports) FailedTags() []names.Tag {
+	var tags []names.Tag
+	for _, id := range r.FailedMachines {
+		tags = append(tags, names.NewMachineTag(id))
+	}
+	for _, name := range r.FailedUnits {
+		tags = append(tags, names.NewUnitTag(name))
+	}
+	return tags
+}
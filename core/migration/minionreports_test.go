@@ -6,6 +6,7 @@ package migration_test
 import (
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/core/migration"
 	coretesting "github.com/juju/juju/testing"
@@ -35,3 +36,29 @@ func (s *MinionReportsSuite) TestIsZeroPhaseSet(c *gc.C) {
 	}
 	c.Check(reports.IsZero(), jc.IsFalse)
 }
+
+func (s *MinionReportsSuite) TestFailedCount(c *gc.C) {
+	reports := migration.MinionReports{
+		FailedMachines: []string{"0", "1"},
+		FailedUnits:    []string{"foo/0"},
+	}
+	c.Check(reports.FailedCount(), gc.Equals, 3)
+}
+
+func (s *MinionReportsSuite) TestFailedTags(c *gc.C) {
+	reports := migration.MinionReports{
+		FailedMachines: []string{"0", "1/lxd/2"},
+		FailedUnits:    []string{"foo/0"},
+	}
+	c.Check(reports.FailedTags(), jc.DeepEquals, []names.Tag{
+		names.NewMachineTag("0"),
+		names.NewMachineTag("1/lxd/2"),
+		names.NewUnitTag("foo/0"),
+	})
+}
+
+func (s *MinionReportsSuite) TestFailedTagsNone(c *gc.C) {
+	reports := migration.MinionReports{}
+	c.Check(reports.FailedCount(), gc.Equals, 0)
+	c.Check(reports.FailedTags(), gc.HasLen, 0)
+}
@@ -0,0 +1,56 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migration
+
+import (
+	"encoding/json"
+
+	"github.com/juju/errors"
+)
+
+// Progress reports how far a migration has got in transferring the
+// model's artifacts to the target controller.
+type Progress struct {
+	// BytesTransferred holds the number of bytes transferred so far.
+	BytesTransferred int64 `json:"bytes-transferred"`
+
+	// TotalBytes holds the number of bytes to be transferred in
+	// total, or zero if that is not yet known.
+	TotalBytes int64 `json:"total-bytes"`
+
+	// CurrentArtifact describes the artifact, such as a charm or
+	// tools tarball, currently being transferred.
+	CurrentArtifact string `json:"current-artifact,omitempty"`
+}
+
+// Percent returns the percentage of the bytes transferred so far, from
+// 0 to 100. It returns 0 if the total is not yet known.
+func (p Progress) Percent() int {
+	if p.TotalBytes <= 0 {
+		return 0
+	}
+	if p.BytesTransferred >= p.TotalBytes {
+		return 100
+	}
+	return int(p.BytesTransferred * 100 / p.TotalBytes)
+}
+
+// Serialize returns p encoded as a string, as delivered in the
+// changes of a progress watcher.
+func (p Progress) Serialize() (string, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	return string(data), nil
+}
+
+// ParseProgress decodes a Progress serialized by Progress.Serialize.
+func ParseProgress(s string) (Progress, error) {
+	var p Progress
+	if err := json.Unmarshal([]byte(s), &p); err != nil {
+		return Progress{}, errors.Annotate(err, "parsing migration progress")
+	}
+	return p, nil
+}
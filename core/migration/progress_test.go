@@ -0,0 +1,55 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package migration_test
+
+import (
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/core/migration"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type ProgressSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(new(ProgressSuite))
+
+func (s *ProgressSuite) TestPercent(c *gc.C) {
+	for i, test := range []struct {
+		transferred, total int64
+		percent            int
+	}{
+		{0, 0, 0},
+		{10, 0, 0},
+		{0, 200, 0},
+		{50, 200, 25},
+		{199, 200, 99},
+		{200, 200, 100},
+		{300, 200, 100},
+	} {
+		c.Logf("test %d: %d/%d", i, test.transferred, test.total)
+		p := migration.Progress{BytesTransferred: test.transferred, TotalBytes: test.total}
+		c.Check(p.Percent(), gc.Equals, test.percent)
+	}
+}
+
+func (s *ProgressSuite) TestSerializeRoundTrip(c *gc.C) {
+	p := migration.Progress{
+		BytesTransferred: 1024,
+		TotalBytes:       4096,
+		CurrentArtifact:  "cs:mysql-42",
+	}
+	s0, err := p.Serialize()
+	c.Assert(err, jc.ErrorIsNil)
+	parsed, err := migration.ParseProgress(s0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(parsed, gc.Equals, p)
+}
+
+func (s *ProgressSuite) TestParseProgressInvalid(c *gc.C) {
+	_, err := migration.ParseProgress("50%")
+	c.Assert(err, gc.ErrorMatches, "parsing migration progress: .*")
+}
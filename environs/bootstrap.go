@@ -60,6 +60,12 @@ type BootstrapParams struct {
 	// that rely on it for selecting images. This will be empty for
 	// providers that do not implements simplestreams.HasRegion.
 	ImageMetadata []*imagemetadata.ImageMetadata
+
+	// Force, if true, allows providers that refuse to bootstrap over
+	// existing controller instances to proceed when none of those
+	// instances is still pending or running, so that a crashed
+	// bootstrap can be recovered from.
+	Force bool
 }
 
 // BootstrapFinalizer is a function returned from Environ.Bootstrap.
@@ -97,6 +103,17 @@ type BootstrapResult struct {
 	Finalize BootstrapFinalizer
 }
 
+// BootstrapChecker is an optional interface that may be implemented
+// by an Environ which can check, without starting any instances, that
+// Bootstrap would be able to proceed with the given parameters.
+type BootstrapChecker interface {
+	// CheckBootstrap performs the checks that Bootstrap would make
+	// before starting the controller instance, such as verifying the
+	// credentials and selecting an image, and returns any error that
+	// Bootstrap would have returned. No instances are started.
+	CheckBootstrap(ctx BootstrapContext, args BootstrapParams) error
+}
+
 // BootstrapContext is an interface that is passed to
 // Environ.Bootstrap, providing a means of obtaining
 // information about and manipulating the context in which
@@ -143,6 +143,16 @@ type BootstrapParams struct {
 
 	// DialOpts contains the bootstrap dial options.
 	DialOpts environs.BootstrapDialOpts
+
+	// Force is passed on to the environ; see
+	// environs.BootstrapParams.Force.
+	Force bool
+
+	// DryRun, if true, causes Bootstrap to perform its checks and
+	// select agent binaries and images as usual, but to return before
+	// starting the controller instance. It is only supported for
+	// environs that implement environs.BootstrapChecker.
+	DryRun bool
 }
 
 // Validate validates the bootstrap parameters.
@@ -172,6 +182,10 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 	}
 
 	cfg := environ.Config()
+	checker, isChecker := environ.(environs.BootstrapChecker)
+	if args.DryRun && !isChecker {
+		return errors.NotSupportedf("dry-run bootstrap of %q model", cfg.Type())
+	}
 	if authKeys := ssh.SplitAuthorisedKeys(cfg.AuthorizedKeys()); len(authKeys) == 0 {
 		// Apparently this can never happen, so it's not tested. But, one day,
 		// Config will act differently (it's pretty crazy that, AFAICT, the
@@ -339,9 +353,7 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 		return err
 	}
 
-	ctx.Verbosef("Starting new instance for initial controller")
-
-	result, err := environ.Bootstrap(ctx, environs.BootstrapParams{
+	bootstrapParams := environs.BootstrapParams{
 		CloudName:            args.CloudName,
 		CloudRegion:          args.CloudRegion,
 		ControllerConfig:     args.ControllerConfig,
@@ -351,7 +363,20 @@ func Bootstrap(ctx environs.BootstrapContext, environ environs.Environ, args Boo
 		Placement:            args.Placement,
 		AvailableTools:       availableTools,
 		ImageMetadata:        imageMetadata,
-	})
+		Force:                args.Force,
+	}
+	if args.DryRun {
+		ctx.Verbosef("Checking that the initial controller can be started")
+		if err := checker.CheckBootstrap(ctx, bootstrapParams); err != nil {
+			return errors.Annotate(err, "dry-run bootstrap failed")
+		}
+		ctx.Infof("Dry-run bootstrap succeeded; no instances were started")
+		return nil
+	}
+
+	ctx.Verbosef("Starting new instance for initial controller")
+
+	result, err := environ.Bootstrap(ctx, bootstrapParams)
 	if err != nil {
 		return err
 	}
@@ -421,9 +446,13 @@ func finalizeInstanceBootstrapConfig(
 	if !hasCACert {
 		return errors.New("controller configuration has no ca-cert")
 	}
+	apiCACert, err := environs.APICACert(controllerCfg)
+	if err != nil {
+		return errors.Trace(err)
+	}
 	icfg.APIInfo = &api.Info{
 		Password: args.AdminSecret,
-		CACert:   caCert,
+		CACert:   apiCACert,
 		ModelTag: names.NewModelTag(cfg.UUID()),
 	}
 	icfg.Controller.MongoInfo = &mongo.MongoInfo{
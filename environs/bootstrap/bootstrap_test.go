@@ -29,6 +29,7 @@ import (
 	"github.com/juju/juju/cloudconfig/instancecfg"
 	"github.com/juju/juju/cmd/modelcmd"
 	"github.com/juju/juju/constraints"
+	"github.com/juju/juju/controller"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/bootstrap"
 	"github.com/juju/juju/environs/config"
@@ -134,6 +135,50 @@ func (s *bootstrapSuite) TestBootstrapEmptyConstraints(c *gc.C) {
 	})
 }
 
+func (s *bootstrapSuite) TestBootstrapDryRunNotSupported(c *gc.C) {
+	env := newEnviron("foo", useDefaultKeys, nil)
+	s.setDummyStorage(c, env)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      "admin-secret",
+		CAPrivateKey:     coretesting.CAKey,
+		DryRun:           true,
+	})
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+	c.Assert(env.bootstrapCount, gc.Equals, 0)
+}
+
+func (s *bootstrapSuite) TestBootstrapDryRun(c *gc.C) {
+	env := &bootstrapEnvironWithChecker{bootstrapEnviron: newEnviron("foo", useDefaultKeys, nil)}
+	s.setDummyStorage(c, env.bootstrapEnviron)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      "admin-secret",
+		CAPrivateKey:     coretesting.CAKey,
+		DryRun:           true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.checkCount, gc.Equals, 1)
+	c.Assert(env.bootstrapCount, gc.Equals, 0)
+	c.Assert(env.checkArgs.AvailableTools, gc.Not(gc.HasLen), 0)
+}
+
+func (s *bootstrapSuite) TestBootstrapDryRunError(c *gc.C) {
+	env := &bootstrapEnvironWithChecker{
+		bootstrapEnviron: newEnviron("foo", useDefaultKeys, nil),
+		checkErr:         errors.New("no images"),
+	}
+	s.setDummyStorage(c, env.bootstrapEnviron)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      "admin-secret",
+		CAPrivateKey:     coretesting.CAKey,
+		DryRun:           true,
+	})
+	c.Assert(err, gc.ErrorMatches, "dry-run bootstrap failed: no images")
+	c.Assert(env.bootstrapCount, gc.Equals, 0)
+}
+
 func (s *bootstrapSuite) TestBootstrapSpecifiedConstraints(c *gc.C) {
 	env := newEnviron("foo", useDefaultKeys, nil)
 	s.setDummyStorage(c, env)
@@ -1030,6 +1075,24 @@ func (s *bootstrapSuite) TestFinishBootstrapConfig(c *gc.C) {
 	c.Assert(err, gc.NotNil)
 }
 
+func (s *bootstrapSuite) TestFinishBootstrapConfigPreviousCACert(c *gc.C) {
+	controllerCfg := coretesting.FakeControllerConfig()
+	controllerCfg[controller.PreviousCACertKey] = coretesting.OtherCACert
+
+	env := newEnviron("foo", useDefaultKeys, nil)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: controllerCfg,
+		AdminSecret:      "admin-secret",
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	icfg := env.instanceConfig
+
+	// API clients accept both CAs; mongo only the current one.
+	c.Check(icfg.APIInfo.CACert, gc.Equals, coretesting.CACert+coretesting.OtherCACert)
+	c.Check(icfg.Controller.MongoInfo.CACert, gc.Equals, coretesting.CACert)
+}
+
 func (s *bootstrapSuite) TestBootstrapMetadataImagesMissing(c *gc.C) {
 	environs.UnregisterImageDataSourceFunc("bootstrap metadata")
 
@@ -1255,6 +1318,19 @@ func (e *bootstrapEnviron) ConstraintsValidator() (constraints.Validator, error)
 	return v, nil
 }
 
+type bootstrapEnvironWithChecker struct {
+	*bootstrapEnviron
+	checkCount int
+	checkArgs  environs.BootstrapParams
+	checkErr   error
+}
+
+func (e *bootstrapEnvironWithChecker) CheckBootstrap(ctx environs.BootstrapContext, args environs.BootstrapParams) error {
+	e.checkCount++
+	e.checkArgs = args
+	return e.checkErr
+}
+
 type bootstrapEnvironWithRegion struct {
 	*bootstrapEnviron
 	region simplestreams.CloudSpec
@@ -176,10 +176,13 @@ func prepare(
 	// TODO(axw) change signature of CACert() to not return a bool.
 	// It's no longer possible to have a controller config without
 	// a CA certificate.
-	caCert, ok := args.ControllerConfig.CACert()
-	if !ok {
+	if _, ok := args.ControllerConfig.CACert(); !ok {
 		return nil, details, errors.New("controller config is missing CA certificate")
 	}
+	caCert, err := environs.APICACert(args.ControllerConfig)
+	if err != nil {
+		return nil, details, errors.Trace(err)
+	}
 
 	// We want to store attributes describing how a controller has been configured.
 	// These do not include the CACert or UUID since they will be replaced with new
@@ -129,6 +129,10 @@ const (
 	// metrics collected in this model for anonymized aggregate analytics.
 	TransmitVendorMetricsKey = "transmit-vendor-metrics"
 
+	// PreferIPv6Key is the key for whether IPv6 addresses are
+	// preferred over IPv4 ones when both are available.
+	PreferIPv6Key = "prefer-ipv6"
+
 	//
 	// Deprecated Settings Attributes
 	//
@@ -295,6 +299,7 @@ var defaultConfigValues = map[string]interface{}{
 	"development":              false,
 	"test-mode":                false,
 	TransmitVendorMetricsKey:   true,
+	PreferIPv6Key:              false,
 
 	// Image and agent streams and URLs.
 	"image-stream":       "released",
@@ -778,6 +783,13 @@ func (c *Config) TransmitVendorMetrics() bool {
 	}
 }
 
+// PreferIPv6 reports whether IPv6 addresses should be preferred over
+// IPv4 ones when both are available. By default IPv4 is preferred.
+func (c *Config) PreferIPv6() bool {
+	v, _ := c.defined[PreferIPv6Key].(bool)
+	return v
+}
+
 // ProvisionerHarvestMode reports the harvesting methodology the
 // provisioner should take.
 func (c *Config) ProvisionerHarvestMode() HarvestMode {
@@ -972,6 +984,7 @@ var alwaysOptional = schema.Defaults{
 	AutomaticallyRetryHooks:      schema.Omit,
 	"test-mode":                  schema.Omit,
 	TransmitVendorMetricsKey:     schema.Omit,
+	PreferIPv6Key:                schema.Omit,
 }
 
 func allowEmpty(attr string) bool {
@@ -1327,4 +1340,9 @@ data of the store. (default false)`,
 		Type:        environschema.Tbool,
 		Group:       environschema.EnvironGroup,
 	},
+	PreferIPv6Key: {
+		Description: "Whether IPv6 addresses are preferred over IPv4 ones when both are available",
+		Type:        environschema.Tbool,
+		Group:       environschema.EnvironGroup,
+	},
 }
@@ -460,6 +460,13 @@ var configTests = []configTest{
 			"resource-tags": []string{"a"},
 		}),
 		err: `resource-tags: expected "key=value", got "a"`,
+	}, {
+		about:       "Resource tags uses a reserved juju key",
+		useDefaults: config.UseDefaults,
+		attrs: minimalConfigAttrs.Merge(testing.Attrs{
+			"resource-tags": []string{"team=a", "juju-model-uuid=b"},
+		}),
+		err: `validating resource tags: tag "juju-model-uuid" uses reserved prefix "juju-"`,
 	}, {
 		about:       "Invalid syslog ca cert format",
 		useDefaults: config.UseDefaults,
@@ -987,6 +994,17 @@ func (s *ConfigSuite) TestAutoHookRetryTrueEnv(c *gc.C) {
 	c.Assert(config.AutomaticallyRetryHooks(), gc.Equals, true)
 }
 
+func (s *ConfigSuite) TestPreferIPv6Default(c *gc.C) {
+	config := newTestConfig(c, testing.Attrs{})
+	c.Assert(config.PreferIPv6(), jc.IsFalse)
+}
+
+func (s *ConfigSuite) TestPreferIPv6(c *gc.C) {
+	config := newTestConfig(c, testing.Attrs{
+		"prefer-ipv6": true})
+	c.Assert(config.PreferIPv6(), jc.IsTrue)
+}
+
 func (s *ConfigSuite) TestProxyValuesWithFallback(c *gc.C) {
 	s.addJujuFiles(c)
 
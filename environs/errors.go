@@ -8,7 +8,20 @@ import (
 )
 
 var (
-	ErrNotBootstrapped  = errors.New("model is not bootstrapped")
-	ErrNoInstances      = errors.NotFoundf("instances")
-	ErrPartialInstances = errors.New("only some instances were found")
+	ErrNotBootstrapped     = errors.New("model is not bootstrapped")
+	ErrAlreadyBootstrapped = errors.New("model is already bootstrapped")
+	ErrNoInstances         = errors.NotFoundf("instances")
+	ErrPartialInstances    = errors.New("only some instances were found")
 )
+
+// IsAlreadyBootstrapped reports whether the cause of err is
+// ErrAlreadyBootstrapped.
+func IsAlreadyBootstrapped(err error) bool {
+	return errors.Cause(err) == ErrAlreadyBootstrapped
+}
+
+// IsNotBootstrapped reports whether the cause of err is
+// ErrNotBootstrapped.
+func IsNotBootstrapped(err error) bool {
+	return errors.Cause(err) == ErrNotBootstrapped
+}
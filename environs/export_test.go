@@ -3,7 +3,26 @@
 
 package environs
 
+import (
+	"golang.org/x/net/context"
+
+	"github.com/juju/juju/api"
+)
+
 var (
 	Providers       = &globalProviders.providers
 	ProviderAliases = &globalProviders.aliases
+
+	SelectAPIAddresses        = selectAPIAddresses
+	SelectAddressType         = selectAddressType
+	APIAddressScopePreference = apiAddressScopePreference
+	WaitControllerInstances   = waitControllerInstances
+	WaitAnyInstanceAddresses  = waitAnyInstanceAddresses
 )
+
+func APIInfoQuorumWithStrategy(
+	controllerUUID, modelUUID, caCert string, apiPort int, env Environ, quorum int,
+	strategy BackoffAttemptStrategy,
+) (*api.Info, error) {
+	return apiInfoQuorum(context.Background(), controllerUUID, modelUUID, caCert, apiPort, env, quorum, strategy)
+}
@@ -6,6 +6,7 @@ package environs
 import (
 	"io"
 
+	"github.com/juju/utils/clock"
 	"gopkg.in/juju/environschema.v1"
 
 	"github.com/juju/juju/cloud"
@@ -14,6 +15,7 @@ import (
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
 	"github.com/juju/juju/storage"
+	"github.com/juju/juju/worker"
 )
 
 // A EnvironProvider represents a computing and storage provider.
@@ -319,3 +321,32 @@ type InstanceTagger interface {
 	// same names, but other existing tags will be left alone.
 	TagInstance(id instance.Id, tags map[string]string) error
 }
+
+// InstanceStatusWatcher is an interface that an Environ may implement
+// if it can report changes to its instances more efficiently than by
+// repeated calls to Instances.
+type InstanceStatusWatcher interface {
+	// WatchInstanceStatus returns a watcher which sends the result of
+	// Instances(ids) on its Changes channel once straight away, and
+	// again whenever the status or addresses of any of the instances
+	// change. The watcher uses clk to time any polling it does. The
+	// caller is responsible for stopping the watcher.
+	WatchInstanceStatus(clk clock.Clock, ids []instance.Id) (InstanceStatusWatch, error)
+}
+
+// InstanceStatusWatch is a worker which reports changes to the status
+// of a set of instances, as returned by
+// InstanceStatusWatcher.WatchInstanceStatus.
+type InstanceStatusWatch interface {
+	worker.Worker
+
+	// Changes returns the channel on which results are sent. The
+	// channel is closed when the watcher stops.
+	Changes() <-chan InstancesResult
+}
+
+// InstancesResult holds the result of a call to Environ.Instances.
+type InstancesResult struct {
+	Instances []instance.Instance
+	Err       error
+}
@@ -487,11 +487,13 @@ func (t *LiveTests) TestBootstrapAndDeploy(c *gc.C) {
 	c.Logf("opening API connection")
 	controllerCfg, err := st.ControllerConfig()
 	c.Assert(err, jc.ErrorIsNil)
-	caCert, _ := controllerCfg.CACert()
-	apiInfo, err := environs.APIInfo(model.Tag().Id(), model.Tag().Id(), caCert, controllerCfg.APIPort(), t.Env)
+	caCert, err := environs.APICACert(controllerCfg)
+	c.Assert(err, jc.ErrorIsNil)
+	apiInfo, err := environs.APIInfoForTag(
+		model.Tag().Id(), model.Tag().Id(), caCert, controllerCfg.APIPort(), t.Env,
+		owner, AdminSecret,
+	)
 	c.Assert(err, jc.ErrorIsNil)
-	apiInfo.Tag = owner
-	apiInfo.Password = AdminSecret
 	apiState, err := api.Open(apiInfo, api.DefaultDialOpts())
 	c.Assert(err, jc.ErrorIsNil)
 	defer apiState.Close()
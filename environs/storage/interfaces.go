@@ -69,3 +69,27 @@ type Storage interface {
 	StorageReader
 	StorageWriter
 }
+
+// A BulkStorageWriter is a StorageWriter that can add many files more
+// efficiently than by adding them one at a time with Put. Use PutFiles
+// rather than calling this directly; it falls back to Put for storage
+// that doesn't implement BulkStorageWriter.
+type BulkStorageWriter interface {
+	StorageWriter
+
+	// PutFiles reads the contents of each file from its reader
+	// and writes it to the named storage file.
+	PutFiles(files map[string]io.Reader) error
+}
+
+// A BulkStorageReader is a StorageReader that can retrieve many files
+// more efficiently than by retrieving them one at a time with Get. Use
+// GetFiles rather than calling this directly; it falls back to Get for
+// storage that doesn't implement BulkStorageReader.
+type BulkStorageReader interface {
+	StorageReader
+
+	// GetFiles opens each of the named storage files, as Get does.
+	// It is the caller's responsibility to close them after use.
+	GetFiles(names []string) (map[string]io.ReadCloser, error)
+}
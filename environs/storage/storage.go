@@ -4,11 +4,16 @@
 package storage
 
 import (
+	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"path"
+	"sync"
 
+	"github.com/juju/errors"
 	"github.com/juju/utils"
+	"github.com/juju/utils/parallel"
 
 	"github.com/juju/juju/environs/simplestreams"
 )
@@ -33,6 +38,85 @@ func RemoveAll(stor Storage) error {
 	return err
 }
 
+// MaxConcurrentTransfers is the maximum number of files that PutFiles
+// and GetFiles transfer concurrently when falling back to Put and Get.
+var MaxConcurrentTransfers = 8
+
+// PutFiles writes each of the given files to stor, reading its contents
+// from the corresponding reader. If stor implements BulkStorageWriter,
+// its PutFiles method is used; otherwise the files are written with up
+// to MaxConcurrentTransfers concurrent calls to Put. All files are
+// attempted, and any errors are returned together.
+func PutFiles(stor StorageWriter, files map[string]io.Reader) error {
+	if bulk, ok := stor.(BulkStorageWriter); ok {
+		return bulk.PutFiles(files)
+	}
+	run := parallel.NewRun(MaxConcurrentTransfers)
+	for name, r := range files {
+		name, r := name, r
+		run.Do(func() error {
+			length, r, err := readerLength(r)
+			if err != nil {
+				return errors.Annotatef(err, "reading %q", name)
+			}
+			return errors.Annotatef(stor.Put(name, r, length), "cannot put %q", name)
+		})
+	}
+	return run.Wait()
+}
+
+// readerLength returns the length of the data remaining in r, and a
+// reader for that data. If r does not report its length, the data is
+// read into memory to determine it.
+func readerLength(r io.Reader) (int64, io.Reader, error) {
+	if lr, ok := r.(interface {
+		Len() int
+	}); ok {
+		return int64(lr.Len()), r, nil
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	return int64(len(data)), bytes.NewReader(data), nil
+}
+
+// GetFiles opens each of the named files in stor, returning a
+// ReadCloser for each keyed by name. It is the caller's responsibility
+// to close them after use. If stor implements BulkStorageReader, its
+// GetFiles method is used; otherwise the files are opened with up to
+// MaxConcurrentTransfers concurrent calls to Get, using stor's default
+// consistency strategy. If any file cannot be opened, those that were
+// opened are closed, and the errors are returned together.
+func GetFiles(stor StorageReader, names []string) (map[string]io.ReadCloser, error) {
+	if bulk, ok := stor.(BulkStorageReader); ok {
+		return bulk.GetFiles(names)
+	}
+	var mu sync.Mutex
+	files := make(map[string]io.ReadCloser)
+	run := parallel.NewRun(MaxConcurrentTransfers)
+	for _, name := range names {
+		name := name
+		run.Do(func() error {
+			r, err := Get(stor, name)
+			if err != nil {
+				return errors.Annotatef(err, "cannot get %q", name)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			files[name] = r
+			return nil
+		})
+	}
+	if err := run.Wait(); err != nil {
+		for _, r := range files {
+			r.Close()
+		}
+		return nil, err
+	}
+	return files, nil
+}
+
 // Get gets the named file from stor using the stor's default consistency strategy.
 func Get(stor StorageReader, name string) (io.ReadCloser, error) {
 	return GetWithRetry(stor, name, stor.DefaultConsistencyStrategy())
@@ -189,3 +189,66 @@ func (s *storageSuite) TestListNoRetryAllowed(c *gc.C) {
 	c.Assert(stor.listPrefix, gc.Equals, "foo")
 	c.Assert(stor.invokeCount, gc.Equals, 1)
 }
+
+type bulkStorage struct {
+	storage.Storage
+	putFiles map[string]io.Reader
+	getNames []string
+}
+
+func (s *bulkStorage) PutFiles(files map[string]io.Reader) error {
+	s.putFiles = files
+	return nil
+}
+
+func (s *bulkStorage) GetFiles(names []string) (map[string]io.ReadCloser, error) {
+	s.getNames = names
+	return nil, nil
+}
+
+func (s *storageSuite) TestPutFiles(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	err = storage.PutFiles(stor, map[string]io.Reader{
+		"a/one.txt": bytes.NewReader([]byte("one")),
+		// A reader that does not report its length.
+		"b/two.txt": struct{ io.Reader }{bytes.NewReader([]byte("two"))},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	files, err := storage.GetFiles(stor, []string{"a/one.txt", "b/two.txt"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(files, gc.HasLen, 2)
+	for name, expect := range map[string]string{"a/one.txt": "one", "b/two.txt": "two"} {
+		data, err := ioutil.ReadAll(files[name])
+		c.Assert(err, jc.ErrorIsNil)
+		files[name].Close()
+		c.Check(string(data), gc.Equals, expect)
+	}
+}
+
+func (s *storageSuite) TestGetFilesNotFound(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	err = stor.Put("one.txt", bytes.NewReader([]byte("one")), 3)
+	c.Assert(err, jc.ErrorIsNil)
+
+	files, err := storage.GetFiles(stor, []string{"one.txt", "missing.txt"})
+	c.Assert(err, gc.ErrorMatches, `cannot get "missing.txt": .*`)
+	c.Assert(files, gc.IsNil)
+}
+
+func (s *storageSuite) TestPutFilesBulk(c *gc.C) {
+	stor := &bulkStorage{}
+	files := map[string]io.Reader{"one.txt": bytes.NewReader([]byte("one"))}
+	err := storage.PutFiles(stor, files)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stor.putFiles, jc.DeepEquals, files)
+}
+
+func (s *storageSuite) TestGetFilesBulk(c *gc.C) {
+	stor := &bulkStorage{}
+	_, err := storage.GetFiles(stor, []string{"one.txt"})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(stor.getNames, jc.DeepEquals, []string{"one.txt"})
+}
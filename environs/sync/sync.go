@@ -178,7 +178,7 @@ func copyTools(toolsDir, stream string, tools []*coretools.Tools, u ToolsUploade
 func copyOneToolsPackage(toolsDir, stream string, tools *coretools.Tools, u ToolsUploader) error {
 	toolsName := envtools.StorageName(tools.Version, toolsDir)
 	logger.Infof("downloading %q %v (%v)", stream, toolsName, tools.URL)
-	resp, err := utils.GetValidatingHTTPClient().Get(tools.URL)
+	resp, err := envtools.DownloadClient.Get(tools.URL)
 	if err != nil {
 		return err
 	}
@@ -16,6 +16,10 @@ import (
 // TODO(katco): 2016-08-09: lp:1611427
 var impatientAttempt = utils.AttemptStrategy{}
 
+// impatientBackoffAttempt is the BackoffAttemptStrategy equivalent of
+// impatientAttempt.
+var impatientBackoffAttempt = environs.BackoffAttemptStrategy{}
+
 // savedAttemptStrategy holds the state needed to restore an AttemptStrategy's
 // original setting.
 //
@@ -82,7 +86,13 @@ func PatchAttemptStrategies(strategies ...*utils.AttemptStrategy) func() {
 		strategies,
 		&common.LongAttempt,
 		&common.ShortAttempt,
-		&environs.AddressesRefreshAttempt,
+		&environs.ControllerInstancesAttempt,
 	)
-	return internalPatchAttemptStrategies(combinedStrategies)
+	restoreStrategies := internalPatchAttemptStrategies(combinedStrategies)
+	originalAddressesAttempt := environs.AddressesRefreshAttempt
+	environs.AddressesRefreshAttempt = impatientBackoffAttempt
+	return func() {
+		environs.AddressesRefreshAttempt = originalAddressesAttempt
+		restoreStrategies()
+	}
 }
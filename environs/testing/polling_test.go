@@ -84,14 +84,16 @@ func (*testingSuite) TestInternalPatchAttemptStrategiesReturnsCleanup(c *gc.C) {
 func (*testingSuite) TestPatchAttemptStrategiesPatchesEnvironsStrategies(c *gc.C) {
 	c.Assert(common.LongAttempt, gc.Not(gc.DeepEquals), impatientAttempt)
 	c.Assert(common.ShortAttempt, gc.Not(gc.DeepEquals), impatientAttempt)
-	c.Assert(environs.AddressesRefreshAttempt, gc.Not(gc.DeepEquals), impatientAttempt)
+	c.Assert(environs.ControllerInstancesAttempt, gc.Not(gc.DeepEquals), impatientAttempt)
+	c.Assert(environs.AddressesRefreshAttempt, gc.Not(gc.DeepEquals), impatientBackoffAttempt)
 
 	cleanup := PatchAttemptStrategies()
 	defer cleanup()
 
 	c.Check(common.LongAttempt, gc.DeepEquals, impatientAttempt)
 	c.Check(common.ShortAttempt, gc.DeepEquals, impatientAttempt)
-	c.Check(environs.AddressesRefreshAttempt, gc.DeepEquals, impatientAttempt)
+	c.Check(environs.ControllerInstancesAttempt, gc.DeepEquals, impatientAttempt)
+	c.Check(environs.AddressesRefreshAttempt, gc.DeepEquals, impatientBackoffAttempt)
 }
 
 func (*testingSuite) TestPatchAttemptStrategiesPatchesGivenAttempts(c *gc.C) {
@@ -23,14 +23,51 @@ const (
 	toolSuffix = ".tgz"
 )
 
+// Layout describes how tools tarballs are named in storage. The name
+// of a tarball is its stream's prefix, followed by the binary version
+// of the tools, followed by the suffix. A Layout other than
+// DefaultLayout can be used to read tools from mirrors that use a
+// different directory convention.
+type Layout struct {
+	// Prefix is formatted with the stream name, using fmt.Sprintf,
+	// to give the prefix of the names of the stream's tarballs. It
+	// must contain exactly one "%s" and no other formatting verbs.
+	Prefix string
+
+	// Suffix is the suffix of the names of all tarballs.
+	Suffix string
+}
+
+// Validate returns an error if the layout's Prefix cannot be
+// formatted with a stream name.
+func (l Layout) Validate() error {
+	if strings.Count(l.Prefix, "%s") != 1 || strings.Count(l.Prefix, "%") != 1 {
+		return fmt.Errorf("invalid tools layout prefix %q: must contain exactly one %%s", l.Prefix)
+	}
+	return nil
+}
+
+// StorageName returns the name of the given version of the juju tools
+// in the given stream. The layout must be valid.
+func (l Layout) StorageName(vers version.Binary, stream string) string {
+	return l.storagePrefix(stream) + vers.String() + l.Suffix
+}
+
+func (l Layout) storagePrefix(stream string) string {
+	return fmt.Sprintf(l.Prefix, stream)
+}
+
+// DefaultLayout is the layout in which juju stores its tools, as
+// "tools/<stream>/juju-<version>.tgz".
+var DefaultLayout = Layout{
+	Prefix: toolPrefix,
+	Suffix: toolSuffix,
+}
+
 // StorageName returns the name that is used to store and retrieve the
 // given version of the juju tools.
 func StorageName(vers version.Binary, stream string) string {
-	return storagePrefix(stream) + vers.String() + toolSuffix
-}
-
-func storagePrefix(stream string) string {
-	return fmt.Sprintf(toolPrefix, stream)
+	return DefaultLayout.StorageName(vers, stream)
 }
 
 // ReadList returns a List of the tools in store with the given major.minor version.
@@ -38,12 +75,21 @@ func storagePrefix(stream string) string {
 // If majorVersion is -1, then all tools tarballs are used.
 // If store contains no such tools, it returns ErrNoMatches.
 func ReadList(stor storage.StorageReader, toolsDir string, majorVersion, minorVersion int) (coretools.List, error) {
+	return DefaultLayout.ReadList(stor, toolsDir, majorVersion, minorVersion)
+}
+
+// ReadList is like the ReadList function, but reads tools stored in
+// the layout l.
+func (l Layout) ReadList(stor storage.StorageReader, toolsDir string, majorVersion, minorVersion int) (coretools.List, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
 	if minorVersion >= 0 {
 		logger.Debugf("reading v%d.%d tools", majorVersion, minorVersion)
 	} else {
 		logger.Debugf("reading v%d.* tools", majorVersion)
 	}
-	storagePrefix := storagePrefix(toolsDir)
+	storagePrefix := l.storagePrefix(toolsDir)
 	names, err := storage.List(stor, storagePrefix)
 	if err != nil {
 		return nil, err
@@ -52,11 +98,12 @@ func ReadList(stor storage.StorageReader, toolsDir string, majorVersion, minorVe
 	var foundAnyTools bool
 	for _, name := range names {
 		name = filepath.ToSlash(name)
-		if !strings.HasPrefix(name, storagePrefix) || !strings.HasSuffix(name, toolSuffix) {
+		suffix := l.Suffix
+		if !strings.HasPrefix(name, storagePrefix) || !strings.HasSuffix(name, suffix) {
 			continue
 		}
 		var t coretools.Tools
-		vers := name[len(storagePrefix) : len(name)-len(toolSuffix)]
+		vers := name[len(storagePrefix) : len(name)-len(suffix)]
 		if t.Version, err = version.ParseBinary(vers); err != nil {
 			logger.Debugf("failed to parse version %q: %v", vers, err)
 			continue
@@ -90,3 +137,51 @@ func ReadList(stor storage.StorageReader, toolsDir string, majorVersion, minorVe
 	}
 	return list, nil
 }
+
+// FindToolsInSources returns a List containing the tools in the given
+// stream, with the given major.minor version number and matching filter,
+// from the first of the given sources that has any. Sources are searched
+// in order, so earlier sources take precedence over later ones, and tools
+// from different sources are never combined.
+// If minorVersion = -1, then only majorVersion is considered.
+// If stream is empty, the released stream is searched.
+// If no source has matching tools, an error satisfying errors.IsNotFound
+// and naming the stream is returned; any other error reading a source is
+// returned immediately.
+func FindToolsInSources(
+	sources []storage.StorageReader, stream string,
+	majorVersion, minorVersion int, filter coretools.Filter,
+) (coretools.List, error) {
+	return DefaultLayout.FindToolsInSources(sources, stream, majorVersion, minorVersion, filter)
+}
+
+// FindToolsInSources is like the FindToolsInSources function, but
+// reads tools stored in the layout l.
+func (l Layout) FindToolsInSources(
+	sources []storage.StorageReader, stream string,
+	majorVersion, minorVersion int, filter coretools.Filter,
+) (coretools.List, error) {
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+	if stream == "" {
+		stream = ReleasedStream
+	}
+	err := ErrNoTools
+	for _, source := range sources {
+		list, readErr := l.ReadList(source, stream, majorVersion, minorVersion)
+		if readErr == nil {
+			list, readErr = list.Match(filter)
+		}
+		switch readErr {
+		case nil:
+			return list, nil
+		case ErrNoTools:
+		case coretools.ErrNoMatches:
+			err = readErr
+		default:
+			return nil, readErr
+		}
+	}
+	return nil, streamToolsError(stream, err)
+}
@@ -4,11 +4,15 @@
 package tools_test
 
 import (
+	"strings"
+
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/version"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/environs/filestorage"
+	"github.com/juju/juju/environs/storage"
 	envtesting "github.com/juju/juju/environs/testing"
 	envtools "github.com/juju/juju/environs/tools"
 	coretesting "github.com/juju/juju/testing"
@@ -27,6 +31,68 @@ func (s *StorageSuite) TestStorageName(c *gc.C) {
 	c.Assert(path, gc.Equals, "tools/proposed/juju-1.2.3-precise-amd64.tgz")
 }
 
+var mirrorLayout = envtools.Layout{
+	Prefix: "mirror/juju/%s/",
+	Suffix: ".tar.gz",
+}
+
+func (s *StorageSuite) TestStorageNameLayout(c *gc.C) {
+	vers := version.MustParseBinary("1.2.3-precise-amd64")
+	path := mirrorLayout.StorageName(vers, "proposed")
+	c.Assert(path, gc.Equals, "mirror/juju/proposed/1.2.3-precise-amd64.tar.gz")
+}
+
+func (s *StorageSuite) TestLayoutValidate(c *gc.C) {
+	c.Assert(envtools.DefaultLayout.Validate(), jc.ErrorIsNil)
+	c.Assert(mirrorLayout.Validate(), jc.ErrorIsNil)
+	for _, prefix := range []string{
+		"mirror/juju/",
+		"mirror/%s/%s/",
+		"mirror/%d/",
+		"mirror/%s/100%%/",
+	} {
+		c.Logf("prefix %q", prefix)
+		err := envtools.Layout{Prefix: prefix, Suffix: ".tgz"}.Validate()
+		c.Check(err, gc.ErrorMatches, `invalid tools layout prefix .*: must contain exactly one %s`)
+	}
+}
+
+func (s *StorageSuite) TestReadListInvalidLayout(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	layout := envtools.Layout{Prefix: "mirror/juju/", Suffix: ".tgz"}
+	_, err = layout.ReadList(stor, "released", 1, -1)
+	c.Assert(err, gc.ErrorMatches, `invalid tools layout prefix "mirror/juju/": must contain exactly one %s`)
+	_, err = layout.FindToolsInSources(
+		[]storage.StorageReader{stor}, "released", 1, -1, coretools.Filter{},
+	)
+	c.Assert(err, gc.ErrorMatches, `invalid tools layout prefix "mirror/juju/": must contain exactly one %s`)
+}
+
+func (s *StorageSuite) TestReadListLayout(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	vers := version.MustParseBinary("1.2.3-precise-amd64")
+	for _, name := range []string{
+		"mirror/juju/released/1.2.3-precise-amd64.tar.gz",
+		// Tools in the default layout are ignored.
+		"tools/released/juju-1.2.4-precise-amd64.tgz",
+	} {
+		err = stor.Put(name, strings.NewReader("tools"), 5)
+		c.Assert(err, jc.ErrorIsNil)
+	}
+
+	list, err := mirrorLayout.FindToolsInSources(
+		[]storage.StorageReader{stor}, "released", 1, -1, coretools.Filter{},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list, gc.HasLen, 1)
+	c.Check(list[0].Version, gc.Equals, vers)
+	url, err := stor.URL("mirror/juju/released/1.2.3-precise-amd64.tar.gz")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(list[0].URL, gc.Equals, url)
+}
+
 func (s *StorageSuite) TestReadListEmpty(c *gc.C) {
 	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
 	c.Assert(err, jc.ErrorIsNil)
@@ -124,3 +190,85 @@ func (*StorageSuite) TestSetenv(c *gc.C) {
 		c.Check(env, gc.DeepEquals, t.expect)
 	}
 }
+
+func (s *StorageSuite) TestFindToolsInSources(c *gc.C) {
+	primary, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	fallback, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	v200 := version.MustParseBinary("2.0.0-xenial-amd64")
+	v205 := version.MustParseBinary("2.0.5-xenial-amd64")
+	primaryTools := envtesting.AssertUploadFakeToolsVersions(c, primary, "released", "released", v200)
+	envtesting.AssertUploadFakeToolsVersions(c, fallback, "released", "released", v205)
+
+	// The newer tools in the fallback are not considered,
+	// since the primary source has compatible tools.
+	list, err := envtools.FindToolsInSources(
+		[]storage.StorageReader{primary, fallback}, "released", 2, 0, coretools.Filter{},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list, jc.DeepEquals, coretools.List{primaryTools[0]})
+}
+
+func (s *StorageSuite) TestFindToolsInSourcesFallback(c *gc.C) {
+	primary, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	fallback, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	v200 := version.MustParseBinary("2.0.0-xenial-amd64")
+	v205 := version.MustParseBinary("2.0.5-xenial-s390x")
+	envtesting.AssertUploadFakeToolsVersions(c, primary, "released", "released", v200)
+	fallbackTools := envtesting.AssertUploadFakeToolsVersions(c, fallback, "released", "released", v205)
+
+	list, err := envtools.FindToolsInSources(
+		[]storage.StorageReader{primary, fallback}, "released", 2, 0, coretools.Filter{Arch: "s390x"},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list, jc.DeepEquals, coretools.List{fallbackTools[0]})
+}
+
+func (s *StorageSuite) TestFindToolsInSourcesNotFound(c *gc.C) {
+	primary, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	fallback, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	v111 := version.MustParseBinary("1.1.1-trusty-amd64")
+	envtesting.AssertUploadFakeToolsVersions(c, fallback, "released", "released", v111)
+
+	_, err = envtools.FindToolsInSources(
+		[]storage.StorageReader{primary, fallback}, "released", 2, 0, coretools.Filter{},
+	)
+	c.Assert(err, gc.ErrorMatches, `searching stream "released": no matching tools available`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+
+	_, err = envtools.FindToolsInSources(
+		[]storage.StorageReader{primary}, "released", 2, 0, coretools.Filter{},
+	)
+	c.Assert(err, gc.ErrorMatches, `searching stream "released": no tools available`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (s *StorageSuite) TestFindToolsInSourcesStream(c *gc.C) {
+	stor, err := filestorage.NewFileStorageWriter(c.MkDir())
+	c.Assert(err, jc.ErrorIsNil)
+	v111 := version.MustParseBinary("1.1.1-trusty-amd64")
+	v112 := version.MustParseBinary("1.1.2-trusty-amd64")
+	released := envtesting.AssertUploadFakeToolsVersions(c, stor, "released", "released", v111)
+	devel := envtesting.AssertUploadFakeToolsVersions(c, stor, "devel", "devel", v112)
+	sources := []storage.StorageReader{stor}
+
+	list, err := envtools.FindToolsInSources(sources, "devel", 1, 1, coretools.Filter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list, gc.HasLen, 1)
+	c.Assert(list[0].Version, gc.Equals, devel[0].Version)
+
+	// The released stream is searched by default.
+	list, err = envtools.FindToolsInSources(sources, "", 1, 1, coretools.Filter{})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(list, gc.HasLen, 1)
+	c.Assert(list[0].Version, gc.Equals, released[0].Version)
+
+	_, err = envtools.FindToolsInSources(sources, "proposed", 1, 1, coretools.Filter{})
+	c.Assert(err, gc.ErrorMatches, `searching stream "proposed": no tools available`)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+}
@@ -5,9 +5,12 @@ package tools
 
 import (
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
+	"github.com/juju/utils"
 	"github.com/juju/utils/arch"
 	"github.com/juju/utils/series"
 	"github.com/juju/version"
@@ -167,6 +170,8 @@ func FindToolsForCloud(sources []simplestreams.DataSource, cloudSpec simplestrea
 }
 
 // FindExactTools returns only the tools that match the supplied version.
+// If there are no such tools, an error satisfying errors.IsNotFound is
+// returned.
 func FindExactTools(env environs.Environ, vers version.Number, series string, arch string) (_ *coretools.Tools, err error) {
 	logger.Infof("finding exact version %s", vers)
 	// Construct a tools filter.
@@ -179,7 +184,9 @@ func FindExactTools(env environs.Environ, vers version.Number, series string, ar
 	stream := PreferredStream(&vers, env.Config().Development(), env.Config().AgentStream())
 	logger.Infof("looking for tools in stream %q", stream)
 	availableTools, err := FindTools(env, vers.Major, vers.Minor, stream, filter)
-	if err != nil {
+	if errors.IsNotFound(err) {
+		return nil, errors.NewNotFound(err, fmt.Sprintf("no matching tools found for version %s", vers))
+	} else if err != nil {
 		return nil, err
 	}
 	if len(availableTools) != 1 {
@@ -215,6 +222,12 @@ func convertToolsError(err *error) {
 	}
 }
 
+// streamToolsError returns a *NotFoundError for the given tools error,
+// naming the stream in which no suitable tools were found.
+func streamToolsError(stream string, err error) error {
+	return errors.NewNotFound(err, fmt.Sprintf("searching stream %q", stream))
+}
+
 // PreferredStream returns the tools stream used to search for tools, based
 // on the required version, whether devel mode is required, and any user specified stream.
 func PreferredStream(vers *version.Number, forceDevel bool, stream string) string {
@@ -234,3 +247,46 @@ func PreferredStream(vers *version.Number, forceDevel bool, stream string) strin
 	}
 	return ReleasedStream
 }
+
+// DownloadClient is the HTTP client used to download tools tarballs.
+// Unlike the client returned by utils.GetValidatingHTTPClient, it
+// reuses connections and has timeouts, so that an unresponsive server
+// cannot block a download indefinitely. Tests may replace it.
+var DownloadClient = newDownloadClient()
+
+func newDownloadClient() *http.Client {
+	// The transport honours proxy settings from the environment and
+	// supports file URLs, as utils.GetValidatingHTTPClient's does.
+	transport := utils.NewHttpTLSTransport(utils.SecureTLSConfig())
+	transport.DisableKeepAlives = false
+	transport.ResponseHeaderTimeout = 30 * time.Second
+	return &http.Client{
+		Transport: transport,
+		Timeout:   15 * time.Minute,
+	}
+}
+
+// VerifyTools downloads the tools tarball at url and checks that its
+// SHA-256 hash is expectedSHA256, as recorded in the tools metadata
+// and returned in the SHA256 field of the tools found by FindTools.
+func VerifyTools(url, expectedSHA256 string) error {
+	if expectedSHA256 == "" {
+		return errors.NotValidf("empty SHA-256 hash")
+	}
+	resp, err := DownloadClient.Get(url)
+	if err != nil {
+		return errors.Annotatef(err, "downloading tools from %q", url)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("downloading tools from %q: %s", url, resp.Status)
+	}
+	sha256, _, err := utils.ReadSHA256(resp.Body)
+	if err != nil {
+		return errors.Annotatef(err, "reading tools from %q", url)
+	}
+	if sha256 != expectedSHA256 {
+		return errors.Errorf("SHA-256 hash mismatch (%v/%v)", sha256, expectedSHA256)
+	}
+	return nil
+}
@@ -4,9 +4,14 @@
 package tools_test
 
 import (
+	"crypto/sha256"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -277,6 +282,7 @@ func (s *SimpleStreamsToolsSuite) TestFindExactTools(c *gc.C) {
 			}
 		} else {
 			c.Check(err, jc.Satisfies, errors.IsNotFound)
+			c.Check(err, gc.ErrorMatches, "no matching tools found for version "+test.seek.Number.String()+": .*")
 		}
 	}
 }
@@ -353,6 +359,64 @@ func fakeToolsList(series ...string) coretools.List {
 	return list
 }
 
+type VerifyToolsSuite struct {
+	coretesting.BaseSuite
+	server *httptest.Server
+}
+
+var _ = gc.Suite(&VerifyToolsSuite{})
+
+const fakeToolsContent = "fake tools tarball"
+
+var fakeToolsSHA256 = fmt.Sprintf("%x", sha256.Sum256([]byte(fakeToolsContent)))
+
+func (s *VerifyToolsSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/tools.tgz" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, fakeToolsContent)
+	}))
+	s.AddCleanup(func(*gc.C) { s.server.Close() })
+}
+
+func (s *VerifyToolsSuite) TestVerifyTools(c *gc.C) {
+	err := envtools.VerifyTools(s.server.URL+"/tools.tgz", fakeToolsSHA256)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *VerifyToolsSuite) TestVerifyToolsMismatch(c *gc.C) {
+	err := envtools.VerifyTools(s.server.URL+"/tools.tgz", "deadbeef")
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(`SHA-256 hash mismatch \(%s/deadbeef\)`, fakeToolsSHA256))
+}
+
+func (s *VerifyToolsSuite) TestVerifyToolsNotFound(c *gc.C) {
+	err := envtools.VerifyTools(s.server.URL+"/missing.tgz", fakeToolsSHA256)
+	c.Assert(err, gc.ErrorMatches, `downloading tools from ".*/missing.tgz": 404 Not Found`)
+}
+
+func (s *VerifyToolsSuite) TestVerifyToolsEmptyHash(c *gc.C) {
+	err := envtools.VerifyTools(s.server.URL+"/tools.tgz", "")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *VerifyToolsSuite) TestVerifyToolsTimeout(c *gc.C) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	client := *envtools.DownloadClient
+	client.Timeout = 10 * time.Millisecond
+	s.PatchValue(&envtools.DownloadClient, &client)
+	err := envtools.VerifyTools(server.URL+"/tools.tgz", fakeToolsSHA256)
+	c.Assert(err, gc.ErrorMatches, `downloading tools from ".*/tools.tgz": .*`)
+}
+
 type ToolsListSuite struct{}
 
 func (s *ToolsListSuite) TestCheckToolsSeriesRequiresTools(c *gc.C) {
@@ -4,84 +4,640 @@
 package environs
 
 import (
+	"strings"
 	"time"
 
 	"github.com/juju/errors"
 	"github.com/juju/utils"
+	"github.com/juju/utils/clock"
+	"github.com/juju/utils/parallel"
+	"golang.org/x/net/context"
 	"gopkg.in/juju/names.v2"
 
 	"github.com/juju/juju/api"
+	"github.com/juju/juju/controller"
 	"github.com/juju/juju/instance"
 	"github.com/juju/juju/network"
+	"github.com/juju/juju/worker"
 )
 
 // AddressesRefreshAttempt is the attempt strategy used when
 // refreshing instance addresses.
-var AddressesRefreshAttempt = utils.AttemptStrategy{
+var AddressesRefreshAttempt = BackoffAttemptStrategy{
 	Total: 3 * time.Minute,
+	Min:   1 * time.Second,
+	Max:   15 * time.Second,
+}
+
+// ControllerInstancesAttempt is the attempt strategy used when
+// waiting for an environment to report its controller instances.
+//
+// TODO(katco): 2016-08-09: lp:1611427
+var ControllerInstancesAttempt = utils.AttemptStrategy{
+	Total: 10 * time.Second,
 	Delay: 1 * time.Second,
 }
 
+// BackoffAttemptStrategy is an attempt strategy whose delay between
+// attempts starts at Min and doubles after each attempt, up to Max.
+// No further attempts are made once Total has elapsed, unless fewer
+// than MinAttempts have been made.
+type BackoffAttemptStrategy struct {
+	Total       time.Duration
+	Min         time.Duration
+	Max         time.Duration
+	MinAttempts int
+}
+
+// backoffFromAttemptStrategy returns a BackoffAttemptStrategy that
+// makes attempts at the fixed interval of the given strategy.
+func backoffFromAttemptStrategy(s utils.AttemptStrategy) BackoffAttemptStrategy {
+	return BackoffAttemptStrategy{
+		Total:       s.Total,
+		Min:         s.Delay,
+		Max:         s.Delay,
+		MinAttempts: s.Min,
+	}
+}
+
+// Start begins a new sequence of attempts.
+func (s BackoffAttemptStrategy) Start() *BackoffAttempt {
+	return &BackoffAttempt{
+		strategy: s,
+		end:      time.Now().Add(s.Total),
+		delay:    s.Min,
+	}
+}
+
+// BackoffAttempt tracks a sequence of attempts made according to a
+// BackoffAttemptStrategy.
+type BackoffAttempt struct {
+	strategy BackoffAttemptStrategy
+	end      time.Time
+	delay    time.Duration
+	count    int
+}
+
+// Next waits until it is time to make the next attempt, and reports
+// whether that attempt should be made. The first attempt is made
+// immediately; false is returned once waiting for another attempt
+// would take the sequence past the strategy's Total, and at least
+// MinAttempts have been made.
+func (a *BackoffAttempt) Next() bool {
+	return a.NextContext(context.Background())
+}
+
+// NextContext is like Next, but stops waiting and returns false as
+// soon as ctx is done.
+func (a *BackoffAttempt) NextContext(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if a.count == 0 {
+		a.count++
+		return true
+	}
+	if a.count >= a.strategy.MinAttempts && !time.Now().Add(a.delay).Before(a.end) {
+		return false
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(a.delay):
+	}
+	a.count++
+	a.delay *= 2
+	if a.delay > a.strategy.Max {
+		a.delay = a.strategy.Max
+	}
+	return true
+}
+
+// MaxConcurrentAddressQueries is the maximum number of instances whose
+// addresses are queried concurrently. It bounds the rate of provider API
+// calls made when an environment has many controller instances.
+var MaxConcurrentAddressQueries = 10
+
 // getAddresses queries and returns the Addresses for the given instances,
 // ignoring nil instances or ones without addresses.
 func getAddresses(instances []instance.Instance) []network.Address {
 	var allAddrs []network.Address
-	for _, inst := range instances {
+	results, _ := getInstanceAddresses(instances)
+	for _, addrs := range results {
+		allAddrs = append(allAddrs, addrs...)
+	}
+	return allAddrs
+}
+
+// getInstanceAddresses queries the Addresses of the given instances
+// concurrently, at most MaxConcurrentAddressQueries at a time. The
+// addresses of each instance, or the error querying them, are returned
+// at the instance's index; nil instances have neither. Errors are
+// logged at debug level and otherwise ignored.
+func getInstanceAddresses(instances []instance.Instance) ([][]network.Address, []error) {
+	maxPar := MaxConcurrentAddressQueries
+	if maxPar < 1 {
+		maxPar = 1
+	}
+	results := make([][]network.Address, len(instances))
+	errs := make([]error, len(instances))
+	run := parallel.NewRun(maxPar)
+	for i, inst := range instances {
 		if inst == nil {
 			continue
 		}
-		addrs, err := inst.Addresses()
-		if err != nil {
-			logger.Debugf(
-				"failed to get addresses for %v: %v (ignoring)",
-				inst.Id(), err,
-			)
-			continue
-		}
-		allAddrs = append(allAddrs, addrs...)
+		i, inst := i, inst
+		run.Do(func() error {
+			addrs, err := inst.Addresses()
+			if err != nil {
+				logger.Debugf(
+					"failed to get addresses (ignoring): instance-id=%v error=%q",
+					inst.Id(), err,
+				)
+				errs[i] = err
+				return nil
+			}
+			results[i] = addrs
+			return nil
+		})
 	}
-	return allAddrs
+	// Errors are logged and ignored above, so there are none to return.
+	run.Wait()
+	return results, errs
 }
 
 // waitAnyInstanceAddresses waits for at least one of the instances
-// to have addresses, and returns them.
+// to have addresses, and returns them. The given strategy determines
+// how long to wait, and how often to check.
+//
+// If env implements InstanceStatusWatcher, the instances are watched
+// rather than polled, using clk to time the wait.
+//
+// Once any instance has addresses, one more attempt is made to gather
+// the addresses of the remaining instances, so that clients are given
+// the addresses of all reachable controllers. Instances that cannot
+// be found, or that still have no addresses after that, are skipped
+// with a warning, and a summary of the skipped instances is logged.
+// Instances whose addresses could not be queried on any attempt are
+// reported individually, with the last error. Any error other than
+// ErrPartialInstances from env.Instances is returned.
+//
+// If ctx is done before then, ctx.Err() is returned.
 func waitAnyInstanceAddresses(
+	ctx context.Context,
+	clk clock.Clock,
 	env Environ,
 	instanceIds []instance.Id,
+	strategy BackoffAttemptStrategy,
 ) ([]network.Address, error) {
+	return waitInstanceAddresses(ctx, clk, env, instanceIds, 0, strategy)
+}
+
+// waitInstanceAddresses is waitAnyInstanceAddresses, except that if
+// quorum is positive, it waits for that many instances to have
+// addresses and returns as soon as they do, without a further attempt.
+// If fewer have addresses when the strategy is exhausted, their
+// addresses are returned with a warning.
+func waitInstanceAddresses(
+	ctx context.Context,
+	clk clock.Clock,
+	env Environ,
+	instanceIds []instance.Id,
+	quorum int,
+	strategy BackoffAttemptStrategy,
+) ([]network.Address, error) {
+	var next func(final bool) ([]instance.Instance, bool, error)
+	if w, ok := env.(InstanceStatusWatcher); ok {
+		watcher, err := w.WatchInstanceStatus(clk, instanceIds)
+		if err != nil {
+			return nil, errors.Annotate(err, "watching instance status")
+		}
+		defer func() {
+			if err := worker.Stop(watcher); err != nil {
+				logger.Debugf("error stopping instance status watcher: %v", err)
+			}
+		}()
+		next = watchInstanceResults(ctx, clk, watcher, strategy)
+	} else {
+		next = pollInstanceResults(ctx, env, instanceIds, strategy)
+	}
+
 	var addrs []network.Address
-	for a := AddressesRefreshAttempt.Start(); len(addrs) == 0 && a.Next(); {
-		instances, err := env.Instances(instanceIds)
+	found := make(map[instance.Id]bool)
+	unresolved := make(map[instance.Id]bool)
+	queried := make(map[instance.Id]int)
+	failures := make(map[instance.Id]int)
+	lastErr := make(map[instance.Id]error)
+	finalPass := false
+	for {
+		instances, ok, err := next(finalPass)
+		if !ok {
+			break
+		}
 		if err != nil && err != ErrPartialInstances {
 			logger.Debugf("error getting state instances: %v", err)
 			return nil, err
 		}
-		addrs = getAddresses(instances)
+		var pending []instance.Instance
+		for i, inst := range instances {
+			if inst == nil {
+				unresolved[instanceIds[i]] = true
+				continue
+			}
+			delete(unresolved, instanceIds[i])
+			if !found[inst.Id()] {
+				pending = append(pending, inst)
+			}
+		}
+		results, errs := getInstanceAddresses(pending)
+		for i, instAddrs := range results {
+			id := pending[i].Id()
+			queried[id]++
+			if errs[i] != nil {
+				failures[id]++
+				lastErr[id] = errs[i]
+				continue
+			}
+			if len(instAddrs) > 0 {
+				found[id] = true
+				addrs = append(addrs, instAddrs...)
+			}
+		}
+		if finalPass || len(found) == len(instanceIds) {
+			break
+		}
+		if quorum > 0 {
+			if len(found) >= quorum {
+				break
+			}
+			continue
+		}
+		finalPass = len(addrs) > 0
 	}
 	if len(addrs) == 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		return nil, errors.NotFoundf("addresses for %v", instanceIds)
 	}
+	if quorum > 0 && len(found) < quorum {
+		logger.Warningf("only %d of %d controller instances have addresses", len(found), quorum)
+	}
+	var missing, noAddresses, failing []instance.Id
+	for _, id := range instanceIds {
+		switch {
+		case found[id]:
+		case unresolved[id]:
+			missing = append(missing, id)
+		case queried[id] > 0 && failures[id] == queried[id]:
+			failing = append(failing, id)
+		default:
+			noAddresses = append(noAddresses, id)
+		}
+	}
+	if len(missing) > 0 {
+		logger.Warningf("controller instances %v not found (skipping)", missing)
+	}
+	if len(noAddresses) > 0 {
+		logger.Warningf("no addresses found for controller instances %v (skipping)", noAddresses)
+	}
+	for _, id := range failing {
+		logger.Warningf(
+			"failed to get addresses on every attempt (skipping): instance-id=%v attempts=%d error=%q",
+			id, queried[id], lastErr[id],
+		)
+	}
+	if skipped := len(missing) + len(noAddresses) + len(failing); skipped > 0 {
+		logger.Infof(
+			"skipped %d of %d controller instances: not-found=%d no-addresses=%d failed=%d",
+			skipped, len(instanceIds), len(missing), len(noAddresses), len(failing),
+		)
+	}
 	return addrs, nil
 }
 
+// pollInstanceResults returns a function which calls env.Instances(ids)
+// each time it is called, waiting between calls according to strategy.
+// The function's second result is false once the strategy is
+// exhausted or ctx is done.
+func pollInstanceResults(
+	ctx context.Context, env Environ, ids []instance.Id, strategy BackoffAttemptStrategy,
+) func(final bool) ([]instance.Instance, bool, error) {
+	a := strategy.Start()
+	return func(bool) ([]instance.Instance, bool, error) {
+		if !a.NextContext(ctx) {
+			return nil, false, nil
+		}
+		instances, err := instancesContext(ctx, env, ids)
+		return instances, true, err
+	}
+}
+
+// watchInstanceResults returns a function which waits for the next
+// result from w's Changes channel. The function's second result is
+// false once ctx is done, the watcher has stopped without error, or
+// the strategy's Total has elapsed on clk; if the watcher stopped
+// with an error, that error is returned. For the final pass, it waits
+// no longer than the strategy's Min, so as not to wait for a change
+// that may never come.
+func watchInstanceResults(
+	ctx context.Context, clk clock.Clock, w InstanceStatusWatch, strategy BackoffAttemptStrategy,
+) func(final bool) ([]instance.Instance, bool, error) {
+	deadline := clk.After(strategy.Total)
+	return func(final bool) ([]instance.Instance, bool, error) {
+		var patience <-chan time.Time
+		if final {
+			patience = clk.After(strategy.Min)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false, nil
+		case <-deadline:
+			return nil, false, nil
+		case <-patience:
+			return nil, false, nil
+		case r, ok := <-w.Changes():
+			if !ok {
+				if err := w.Wait(); err != nil {
+					return nil, true, errors.Annotate(err, "watching instance status")
+				}
+				return nil, false, nil
+			}
+			return r.Instances, true, r.Err
+		}
+	}
+}
+
+// instancesContext returns the result of env.Instances(ids), or
+// ctx.Err() if ctx is done first. The provider call cannot itself be
+// interrupted, so it is left to complete in the background.
+func instancesContext(ctx context.Context, env Environ, ids []instance.Id) ([]instance.Instance, error) {
+	type result struct {
+		instances []instance.Instance
+		err       error
+	}
+	// The channel is buffered so that a call which completes after
+	// ctx is done doesn't leak its goroutine.
+	results := make(chan result, 1)
+	go func() {
+		instances, err := env.Instances(ids)
+		results <- result{instances, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-results:
+		return r.instances, r.err
+	}
+}
+
+// waitControllerInstances returns the IDs of the environment's
+// controller instances, retrying according to ControllerInstancesAttempt
+// while the environment reports none or fails to report them with a
+// transient error. Errors satisfying IsNotBootstrapped or
+// errors.IsUnauthorized are returned immediately, since retrying will
+// not clear them. If none are reported within the strategy's window,
+// the last result is returned. The delay between attempts is measured
+// by clk; if ctx is done first, ctx.Err() is returned without waiting
+// for the delay to expire.
+func waitControllerInstances(
+	ctx context.Context, clk clock.Clock, env Environ, controllerUUID string,
+) ([]instance.Id, error) {
+	strategy := ControllerInstancesAttempt
+	deadline := clk.Now().Add(strategy.Total)
+	for attempt := 1; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		instanceIds, err := env.ControllerInstances(controllerUUID)
+		if err == nil && len(instanceIds) > 0 {
+			return instanceIds, nil
+		}
+		if IsNotBootstrapped(err) || errors.IsUnauthorized(err) {
+			return nil, err
+		}
+		if !clk.Now().Add(strategy.Delay).Before(deadline) && attempt >= strategy.Min {
+			return instanceIds, err
+		}
+		logger.Debugf("waiting for controller instances (err: %v)", err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-clk.After(strategy.Delay):
+		}
+	}
+}
+
+// apiAddressScopePreference holds the address scopes, most preferred
+// first, used to choose which controller addresses APIInfo returns.
+var apiAddressScopePreference = []network.Scope{
+	network.ScopePublic,
+	network.ScopeCloudLocal,
+}
+
+// selectAPIAddresses returns those of addrs with the first scope in
+// scopePreference that any of them has. If none of the addresses has
+// a preferred scope, addrs is returned unchanged.
+func selectAPIAddresses(addrs []network.Address, scopePreference []network.Scope) []network.Address {
+	for _, scope := range scopePreference {
+		var selected []network.Address
+		for _, addr := range addrs {
+			if addr.Scope == scope {
+				selected = append(selected, addr)
+			}
+		}
+		if len(selected) > 0 {
+			return selected
+		}
+	}
+	return addrs
+}
+
+// selectAddressType returns those of addrs that are hostnames or IP
+// addresses of the preferred version. If none of the IP addresses is
+// of the preferred version, addrs is returned unchanged.
+func selectAddressType(addrs []network.Address, preferIPv6 bool) []network.Address {
+	preferred, other := network.IPv4Address, network.IPv6Address
+	if preferIPv6 {
+		preferred, other = other, preferred
+	}
+	var selected []network.Address
+	var found bool
+	for _, addr := range addrs {
+		switch addr.Type {
+		case preferred:
+			found = true
+		case other:
+			continue
+		}
+		selected = append(selected, addr)
+	}
+	if !found {
+		return addrs
+	}
+	return selected
+}
+
 // APIInfo returns an api.Info for the environment. The result is populated
-// with addresses and CA certificate, but no tag or password.
+// with addresses and CA certificate, but no tag or password. Public
+// addresses are preferred over cloud-local ones; other addresses are
+// only returned if there are neither. Where both IPv4 and IPv6 addresses
+// are available, only those of the version chosen by the model's
+// prefer-ipv6 setting are returned.
 func APIInfo(controllerUUID, modelUUID, caCert string, apiPort int, env Environ) (*api.Info, error) {
-	instanceIds, err := env.ControllerInstances(controllerUUID)
+	return APIInfoContext(context.Background(), controllerUUID, modelUUID, caCert, apiPort, env)
+}
+
+// APIInfoForTag returns an api.Info for the environment, as APIInfo
+// does, with its Tag and Password set so that it can be used to
+// connect as the given entity.
+func APIInfoForTag(
+	controllerUUID, modelUUID, caCert string, apiPort int, env Environ,
+	tag names.Tag, password string,
+) (*api.Info, error) {
+	info, err := APIInfo(controllerUUID, modelUUID, caCert, apiPort, env)
+	if err != nil {
+		return nil, err
+	}
+	info.Tag = tag
+	info.Password = password
+	return info, nil
+}
+
+// APIInfoContext returns an api.Info for the environment, as APIInfo
+// does. If ctx is done while waiting for the controller instances or
+// their addresses, it stops waiting and returns ctx.Err().
+func APIInfoContext(
+	ctx context.Context,
+	controllerUUID, modelUUID, caCert string, apiPort int, env Environ,
+) (*api.Info, error) {
+	return apiInfo(ctx, controllerUUID, modelUUID, caCert, apiPort, env, AddressesRefreshAttempt)
+}
+
+// APIInfoWithStrategy returns an api.Info for the environment, as
+// APIInfo does, waiting for the controller instances' addresses
+// according to the given strategy rather than AddressesRefreshAttempt.
+// Attempts are made at the strategy's fixed Delay. If no addresses are
+// found within the strategy's window, an error satisfying
+// errors.IsNotFound is returned.
+func APIInfoWithStrategy(
+	controllerUUID, modelUUID, caCert string, apiPort int, env Environ,
+	strategy utils.AttemptStrategy,
+) (*api.Info, error) {
+	return APIInfoWithBackoff(
+		controllerUUID, modelUUID, caCert, apiPort, env,
+		backoffFromAttemptStrategy(strategy),
+	)
+}
+
+// APIInfoWithBackoff returns an api.Info for the environment, as
+// APIInfoWithStrategy does, but with the delay between attempts
+// growing according to the given strategy.
+func APIInfoWithBackoff(
+	controllerUUID, modelUUID, caCert string, apiPort int, env Environ,
+	strategy BackoffAttemptStrategy,
+) (*api.Info, error) {
+	return apiInfo(context.Background(), controllerUUID, modelUUID, caCert, apiPort, env, strategy)
+}
+
+func apiInfo(
+	ctx context.Context,
+	controllerUUID, modelUUID, caCert string, apiPort int, env Environ,
+	strategy BackoffAttemptStrategy,
+) (*api.Info, error) {
+	instanceIds, err := waitControllerInstances(ctx, clock.WallClock, env, controllerUUID)
 	if err != nil {
 		return nil, err
 	}
 	logger.Debugf("ControllerInstances returned: %v", instanceIds)
-	addrs, err := waitAnyInstanceAddresses(env, instanceIds)
+	return apiInfoForInstances(ctx, modelUUID, caCert, apiPort, env, instanceIds, strategy)
+}
+
+// APIInfoQuorum returns an api.Info for the environment, as APIInfo
+// does, except that it waits for at least quorum of the controller
+// instances to have addresses, rather than any one, and returns as soon
+// as they do. If fewer than quorum have addresses by the end of
+// AddressesRefreshAttempt, the addresses of those that do are returned.
+func APIInfoQuorum(
+	controllerUUID, modelUUID, caCert string, apiPort int, env Environ, quorum int,
+) (*api.Info, error) {
+	return apiInfoQuorum(context.Background(), controllerUUID, modelUUID, caCert, apiPort, env, quorum, AddressesRefreshAttempt)
+}
+
+func apiInfoQuorum(
+	ctx context.Context,
+	controllerUUID, modelUUID, caCert string, apiPort int, env Environ, quorum int,
+	strategy BackoffAttemptStrategy,
+) (*api.Info, error) {
+	if quorum < 1 {
+		return nil, errors.NotValidf("quorum %d", quorum)
+	}
+	instanceIds, err := waitControllerInstances(ctx, clock.WallClock, env, controllerUUID)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := waitInstanceAddresses(ctx, clock.WallClock, env, instanceIds, quorum, strategy)
+	if err != nil {
+		return nil, err
+	}
+	return newAPIInfo(modelUUID, caCert, apiPort, env, addrs), nil
+}
+
+// APIInfoForInstances returns an api.Info for the environment, as
+// APIInfo does, but using the addresses of the given controller
+// instances rather than discovering them with ControllerInstances.
+// This allows callers to choose controllers by zone or health.
+func APIInfoForInstances(
+	modelUUID, caCert string, apiPort int, env Environ, ids []instance.Id,
+) (*api.Info, error) {
+	if len(ids) == 0 {
+		return nil, errors.NotValidf("empty instance ids")
+	}
+	return apiInfoForInstances(context.Background(), modelUUID, caCert, apiPort, env, ids, AddressesRefreshAttempt)
+}
+
+func apiInfoForInstances(
+	ctx context.Context,
+	modelUUID, caCert string, apiPort int, env Environ, instanceIds []instance.Id,
+	strategy BackoffAttemptStrategy,
+) (*api.Info, error) {
+	addrs, err := waitAnyInstanceAddresses(ctx, clock.WallClock, env, instanceIds, strategy)
 	if err != nil {
 		return nil, err
 	}
+	return newAPIInfo(modelUUID, caCert, apiPort, env, addrs), nil
+}
+
+// newAPIInfo returns an api.Info holding the preferred API addresses
+// of the given controller addresses.
+func newAPIInfo(modelUUID, caCert string, apiPort int, env Environ, addrs []network.Address) *api.Info {
+	addrs = selectAPIAddresses(addrs, apiAddressScopePreference)
+	addrs = selectAddressType(addrs, env.Config().PreferIPv6())
 	apiAddrs := network.HostPortsToStrings(
 		network.AddressesWithPort(addrs, apiPort),
 	)
 	modelTag := names.NewModelTag(modelUUID)
-	apiInfo := &api.Info{Addrs: apiAddrs, CACert: caCert, ModelTag: modelTag}
-	return apiInfo, nil
+	return &api.Info{Addrs: apiAddrs, CACert: caCert, ModelTag: modelTag}
+}
+
+// APICACert returns the CA certificates that API clients of the
+// controller should accept, for passing to APIInfo. While the
+// controller's CA certificate is being rotated, the result is a PEM
+// bundle holding both the current and the previous CA certificates;
+// otherwise it is just the current one.
+func APICACert(cfg controller.Config) (string, error) {
+	caCert, ok := cfg.CACert()
+	if !ok || caCert == "" {
+		return "", errors.New("config has no CACert")
+	}
+	previousCACert, ok := cfg.PreviousCACert()
+	if !ok {
+		return caCert, nil
+	}
+	if !strings.HasSuffix(caCert, "\n") {
+		caCert += "\n"
+	}
+	return caCert + previousCACert, nil
 }
 
 // CheckProviderAPI returns an error if a simple API call
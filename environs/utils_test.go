@@ -0,0 +1,880 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package environs_test
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/loggo"
+	jujutesting "github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	"github.com/juju/utils/clock"
+	"golang.org/x/net/context"
+	gc "gopkg.in/check.v1"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/cert"
+	"github.com/juju/juju/controller"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/config"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/testing"
+)
+
+type utilsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&utilsSuite{})
+
+var impatientStrategy = environs.BackoffAttemptStrategy{
+	Total: 50 * time.Millisecond,
+	Min:   10 * time.Millisecond,
+	Max:   10 * time.Millisecond,
+}
+
+var impatientFixedStrategy = utils.AttemptStrategy{
+	Total: 50 * time.Millisecond,
+	Delay: 10 * time.Millisecond,
+}
+
+var impatientControllerInstancesAttempt = utils.AttemptStrategy{
+	Total: 50 * time.Millisecond,
+	Delay: 5 * time.Millisecond,
+}
+
+func (s *utilsSuite) TestBackoffAttemptStrategy(c *gc.C) {
+	strategy := environs.BackoffAttemptStrategy{
+		Total: 90 * time.Millisecond,
+		Min:   5 * time.Millisecond,
+		Max:   20 * time.Millisecond,
+	}
+	var times []time.Time
+	for a := strategy.Start(); a.Next(); {
+		times = append(times, time.Now())
+	}
+	// Delays of 5, 10, 20, 20, 20ms fit within the window; a fixed
+	// delay of Min would have allowed many more attempts.
+	c.Assert(len(times), jc.GreaterThan, 1)
+	c.Assert(len(times), jc.LessThan, 7)
+	for i := 1; i < len(times); i++ {
+		expect := strategy.Min << uint(i-1)
+		if expect > strategy.Max {
+			expect = strategy.Max
+		}
+		c.Check(times[i].Sub(times[i-1]) >= expect, jc.IsTrue)
+	}
+}
+
+func (s *utilsSuite) TestBackoffAttemptStrategyZero(c *gc.C) {
+	var count int
+	for a := (environs.BackoffAttemptStrategy{}).Start(); a.Next(); {
+		count++
+	}
+	c.Assert(count, gc.Equals, 1)
+}
+
+func (s *utilsSuite) TestBackoffAttemptStrategyMinAttempts(c *gc.C) {
+	var count int
+	for a := (environs.BackoffAttemptStrategy{MinAttempts: 3}).Start(); a.Next(); {
+		count++
+	}
+	c.Assert(count, gc.Equals, 3)
+}
+
+func (s *utilsSuite) TestAPIInfoWithStrategy(c *gc.C) {
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+		},
+	}
+	info, err := environs.APIInfoWithStrategy(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientFixedStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070"})
+	c.Assert(info.CACert, gc.Equals, testing.CACert)
+	c.Assert(info.ModelTag, gc.Equals, testing.ModelTag)
+}
+
+func (s *utilsSuite) TestAPIInfoForTag(c *gc.C) {
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+		},
+	}
+	tag := names.NewMachineTag("0")
+	info, err := environs.APIInfoForTag(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, tag, "sekrit",
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070"})
+	c.Assert(info.ModelTag, gc.Equals, testing.ModelTag)
+	c.Assert(info.Tag, gc.Equals, tag)
+	c.Assert(info.Password, gc.Equals, "sekrit")
+}
+
+func (s *utilsSuite) TestAPIInfoForInstances(c *gc.C) {
+	env := &mockEnviron{
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+			"i-1": {id: "i-1", addrs: network.NewAddresses("0.1.2.4")},
+		},
+	}
+	info, err := environs.APIInfoForInstances(
+		testing.ModelTag.Id(), testing.CACert, 17070, env, []instance.Id{"i-1"},
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.4:17070"})
+	c.Assert(info.CACert, gc.Equals, testing.CACert)
+	c.Assert(info.ModelTag, gc.Equals, testing.ModelTag)
+	c.Assert(env.controllerInstancesCalls, gc.Equals, 0)
+}
+
+func (s *utilsSuite) TestAPIInfoForInstancesEmpty(c *gc.C) {
+	_, err := environs.APIInfoForInstances(
+		testing.ModelTag.Id(), testing.CACert, 17070, &mockEnviron{}, nil,
+	)
+	c.Assert(err, gc.ErrorMatches, "empty instance ids not valid")
+}
+
+func (s *utilsSuite) TestAPIInfoPrefersPublicAddresses(c *gc.C) {
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0", "i-1"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("10.0.0.1", "0.1.2.3")},
+			"i-1": {id: "i-1", addrs: network.NewAddresses("10.0.0.2")},
+		},
+	}
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070"})
+}
+
+func (s *utilsSuite) TestAPICACert(c *gc.C) {
+	caCert, err := environs.APICACert(controller.Config{
+		controller.CACertKey: testing.CACert,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(caCert, gc.Equals, testing.CACert)
+}
+
+func (s *utilsSuite) TestAPICACertPrevious(c *gc.C) {
+	previousCACert, _, err := cert.NewCA("previous", "1", time.Now().AddDate(1, 0, 0))
+	c.Assert(err, jc.ErrorIsNil)
+	caCert, err := environs.APICACert(controller.Config{
+		controller.CACertKey:         testing.CACert,
+		controller.PreviousCACertKey: previousCACert,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	xcerts, err := cert.ParseCerts(caCert)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(xcerts, gc.HasLen, 2)
+	c.Check(xcerts[0].Subject.CommonName, gc.Equals, `juju-generated CA for model "juju testing"`)
+	c.Check(xcerts[1].Subject.CommonName, gc.Equals, `juju-generated CA for model "previous"`)
+}
+
+func (s *utilsSuite) TestAPICACertMissing(c *gc.C) {
+	_, err := environs.APICACert(controller.Config{})
+	c.Assert(err, gc.ErrorMatches, "config has no CACert")
+}
+
+func (s *utilsSuite) TestSelectAPIAddresses(c *gc.C) {
+	public := network.NewScopedAddress("0.1.2.3", network.ScopePublic)
+	cloudLocal := network.NewScopedAddress("10.0.0.1", network.ScopeCloudLocal)
+	machineLocal := network.NewScopedAddress("127.0.0.1", network.ScopeMachineLocal)
+	unknown := network.NewScopedAddress("example.com", network.ScopeUnknown)
+	publicHost := network.NewScopedAddress("ec2-0-1-2-3.compute.amazonaws.com", network.ScopePublic)
+	cloudLocalHost := network.NewScopedAddress("ip-10-0-0-1.ec2.internal", network.ScopeCloudLocal)
+
+	for i, test := range []struct {
+		addrs    []network.Address
+		expected []network.Address
+	}{{
+		addrs:    []network.Address{cloudLocal, public, machineLocal},
+		expected: []network.Address{public},
+	}, {
+		addrs:    []network.Address{machineLocal, cloudLocal, unknown},
+		expected: []network.Address{cloudLocal},
+	}, {
+		addrs:    []network.Address{public, cloudLocal, publicHost, cloudLocalHost},
+		expected: []network.Address{public, publicHost},
+	}, {
+		addrs:    []network.Address{cloudLocal, cloudLocalHost},
+		expected: []network.Address{cloudLocal, cloudLocalHost},
+	}, {
+		addrs:    []network.Address{unknown, machineLocal},
+		expected: []network.Address{unknown, machineLocal},
+	}, {
+		addrs:    nil,
+		expected: nil,
+	}} {
+		c.Logf("test %d: %v", i, test.addrs)
+		addrs := environs.SelectAPIAddresses(test.addrs, environs.APIAddressScopePreference)
+		c.Check(addrs, jc.DeepEquals, test.expected)
+	}
+}
+
+func (s *utilsSuite) TestSelectAddressType(c *gc.C) {
+	ipv4 := network.NewAddress("0.1.2.3")
+	ipv6 := network.NewAddress("2001:db8::1")
+	host := network.NewAddress("example.com")
+
+	for i, test := range []struct {
+		addrs      []network.Address
+		preferIPv6 bool
+		expected   []network.Address
+	}{{
+		addrs:    []network.Address{ipv6, ipv4, host},
+		expected: []network.Address{ipv4, host},
+	}, {
+		addrs:      []network.Address{ipv6, ipv4, host},
+		preferIPv6: true,
+		expected:   []network.Address{ipv6, host},
+	}, {
+		addrs:    []network.Address{ipv6, host},
+		expected: []network.Address{ipv6, host},
+	}, {
+		addrs:      []network.Address{ipv4},
+		preferIPv6: true,
+		expected:   []network.Address{ipv4},
+	}, {
+		addrs:    nil,
+		expected: nil,
+	}} {
+		c.Logf("test %d: %v (prefer IPv6: %v)", i, test.addrs, test.preferIPv6)
+		addrs := environs.SelectAddressType(test.addrs, test.preferIPv6)
+		c.Check(addrs, jc.DeepEquals, test.expected)
+	}
+}
+
+func (s *utilsSuite) TestAPIInfoDualStack(c *gc.C) {
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3", "2001:db8::1")},
+		},
+	}
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070"})
+
+	env.config = testing.CustomModelConfig(c, testing.Attrs{"prefer-ipv6": true})
+	info, err = environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"[2001:db8::1]:17070"})
+}
+
+func (s *utilsSuite) TestAPIInfoContextCancelled(c *gc.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+		},
+	}
+	_, err := environs.APIInfoContext(
+		ctx, testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env,
+	)
+	c.Assert(err, gc.Equals, context.Canceled)
+	c.Assert(env.controllerInstancesCalls, gc.Equals, 0)
+}
+
+func (s *utilsSuite) TestAPIInfoContextCancelledWaitingForAddresses(c *gc.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0"},
+		},
+	}
+	env.instancesHook = cancel
+	// APIInfoContext would otherwise wait for AddressesRefreshAttempt.
+	_, err := environs.APIInfoContext(
+		ctx, testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env,
+	)
+	c.Assert(err, gc.Equals, context.Canceled)
+	c.Assert(env.instancesCalls, gc.Equals, 1)
+}
+
+func (s *utilsSuite) TestAPIInfoContextCancelledDuringInstances(c *gc.C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	unblock := make(chan struct{})
+	defer close(unblock)
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+		},
+	}
+	env.instancesHook = func() {
+		cancel()
+		<-unblock
+	}
+	_, err := environs.APIInfoContext(
+		ctx, testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env,
+	)
+	c.Assert(err, gc.Equals, context.Canceled)
+}
+
+func (s *utilsSuite) TestAPIInfoWithStrategyNoAddresses(c *gc.C) {
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0"},
+		},
+	}
+	_, err := environs.APIInfoWithStrategy(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientFixedStrategy,
+	)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(env.instancesCalls, jc.GreaterThan, 1)
+}
+
+func (s *utilsSuite) TestAPIInfoWithBackoffNoAddresses(c *gc.C) {
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0"},
+		},
+	}
+	_, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(env.instancesCalls, jc.GreaterThan, 1)
+}
+
+func (s *utilsSuite) TestAPIInfoControllerInstancesRetried(c *gc.C) {
+	s.PatchValue(&environs.ControllerInstancesAttempt, impatientControllerInstancesAttempt)
+	env := &mockEnviron{
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+		},
+	}
+	env.controllerInstancesHook = func() error {
+		// The instance is reported on the third call, after
+		// first an error and then an empty list.
+		switch env.controllerInstancesCalls {
+		case 1:
+			return errors.New("not yet")
+		case 3:
+			env.controllerInstances = []instance.Id{"i-0"}
+		}
+		return nil
+	}
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070"})
+	c.Assert(env.controllerInstancesCalls, gc.Equals, 3)
+}
+
+func (s *utilsSuite) TestAPIInfoControllerInstancesFirstTry(c *gc.C) {
+	s.PatchValue(&environs.ControllerInstancesAttempt, impatientControllerInstancesAttempt)
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+		},
+	}
+	_, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(env.controllerInstancesCalls, gc.Equals, 1)
+}
+
+func (s *utilsSuite) TestAPIInfoControllerInstancesError(c *gc.C) {
+	s.PatchValue(&environs.ControllerInstancesAttempt, impatientControllerInstancesAttempt)
+	env := &mockEnviron{}
+	env.controllerInstancesHook = func() error {
+		return errors.Errorf("failure %d", env.controllerInstancesCalls)
+	}
+	_, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf("failure %d", env.controllerInstancesCalls))
+	c.Assert(env.controllerInstancesCalls, jc.GreaterThan, 1)
+}
+
+func (s *utilsSuite) TestAPIInfoControllerInstancesPermanentError(c *gc.C) {
+	s.PatchValue(&environs.ControllerInstancesAttempt, impatientControllerInstancesAttempt)
+	for i, permanent := range []error{
+		environs.ErrNotBootstrapped,
+		errors.Unauthorizedf("bad credentials"),
+	} {
+		c.Logf("test %d: %v", i, permanent)
+		env := &mockEnviron{}
+		env.controllerInstancesHook = func() error {
+			return permanent
+		}
+		_, err := environs.APIInfoWithBackoff(
+			testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+		)
+		c.Check(errors.Cause(err), gc.Equals, permanent)
+		c.Check(env.controllerInstancesCalls, gc.Equals, 1)
+	}
+}
+
+func (s *utilsSuite) TestWaitControllerInstancesClockDelay(c *gc.C) {
+	clock := jujutesting.NewClock(time.Time{})
+	env := &mockEnviron{}
+	env.controllerInstancesHook = func() error {
+		if env.controllerInstancesCalls > 1 {
+			env.controllerInstances = []instance.Id{"i-0"}
+		}
+		return nil
+	}
+	type result struct {
+		ids []instance.Id
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		ids, err := environs.WaitControllerInstances(context.Background(), clock, env, "")
+		done <- result{ids, err}
+	}()
+
+	select {
+	case <-clock.Alarms():
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for retry delay")
+	}
+	clock.Advance(environs.ControllerInstancesAttempt.Delay)
+	select {
+	case r := <-done:
+		c.Assert(r.err, jc.ErrorIsNil)
+		c.Assert(r.ids, jc.DeepEquals, []instance.Id{"i-0"})
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for controller instances")
+	}
+	c.Assert(env.controllerInstancesCalls, gc.Equals, 2)
+}
+
+func (s *utilsSuite) TestWaitControllerInstancesCancelledDuringDelay(c *gc.C) {
+	clock := jujutesting.NewClock(time.Time{})
+	env := &mockEnviron{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		_, err := environs.WaitControllerInstances(ctx, clock, env, "")
+		done <- err
+	}()
+
+	select {
+	case <-clock.Alarms():
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for retry delay")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		c.Assert(err, gc.Equals, context.Canceled)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("cancellation did not interrupt the retry delay")
+	}
+	c.Assert(env.controllerInstancesCalls, gc.Equals, 1)
+}
+
+func (s *utilsSuite) TestAPIInfoAllControllerAddresses(c *gc.C) {
+	inst0 := &mockInstance{id: "i-0", addrs: network.NewAddresses("0.1.2.3")}
+	inst1 := &mockInstance{id: "i-1"}
+	inst2 := &mockInstance{id: "i-2"}
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0", "i-1", "i-2"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": inst0, "i-1": inst1, "i-2": inst2,
+		},
+	}
+	env.instancesHook = func() {
+		// i-1 reports its address after i-0; i-2 never does.
+		if env.instancesCalls == 2 {
+			inst1.addrs = network.NewAddresses("0.1.2.4")
+		}
+	}
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070", "0.1.2.4:17070"})
+	c.Assert(env.instancesCalls, gc.Equals, 2)
+}
+
+func (s *utilsSuite) TestAPIInfoQuorum(c *gc.C) {
+	inst0 := &mockInstance{id: "i-0", addrs: network.NewAddresses("0.1.2.3")}
+	inst1 := &mockInstance{id: "i-1"}
+	inst2 := &mockInstance{id: "i-2"}
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0", "i-1", "i-2"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": inst0, "i-1": inst1, "i-2": inst2,
+		},
+	}
+	env.instancesHook = func() {
+		// i-1 reports its address after i-0; i-2 never does.
+		if env.instancesCalls == 2 {
+			inst1.addrs = network.NewAddresses("0.1.2.4")
+		}
+	}
+	info, err := environs.APIInfoQuorumWithStrategy(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, 2, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070", "0.1.2.4:17070"})
+	// The quorum was reached on the second attempt, so no
+	// further attempt is made for i-2.
+	c.Assert(env.instancesCalls, gc.Equals, 2)
+}
+
+func (s *utilsSuite) TestAPIInfoQuorumNotReached(c *gc.C) {
+	var tw loggo.TestWriter
+	c.Assert(loggo.RegisterWriter("quorum-tester", &tw), gc.IsNil)
+	defer loggo.RemoveWriter("quorum-tester")
+
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0", "i-1", "i-2"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+			"i-1": {id: "i-1"},
+			"i-2": {id: "i-2"},
+		},
+	}
+	info, err := environs.APIInfoQuorumWithStrategy(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, 2, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070"})
+	c.Assert(env.instancesCalls, jc.GreaterThan, 2)
+	c.Check(tw.Log(), jc.LogMatches, []jc.SimpleMessage{
+		{loggo.WARNING, `only 1 of 2 controller instances have addresses`},
+		{loggo.WARNING, `no addresses found for controller instances \[i-1 i-2\] \(skipping\)`},
+	})
+}
+
+func (s *utilsSuite) TestAPIInfoQuorumInvalid(c *gc.C) {
+	_, err := environs.APIInfoQuorum(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, &mockEnviron{}, 0,
+	)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(err, gc.ErrorMatches, "quorum 0 not valid")
+}
+
+func (s *utilsSuite) TestAPIInfoPartialInstances(c *gc.C) {
+	var tw loggo.TestWriter
+	c.Assert(loggo.RegisterWriter("partial-tester", &tw), gc.IsNil)
+	defer loggo.RemoveWriter("partial-tester")
+
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0", "i-1", "i-2"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+			"i-2": {id: "i-2"},
+		},
+	}
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070"})
+	c.Check(tw.Log(), jc.LogMatches, []jc.SimpleMessage{
+		{loggo.WARNING, `controller instances \[i-1\] not found \(skipping\)`},
+		{loggo.WARNING, `no addresses found for controller instances \[i-2\] \(skipping\)`},
+	})
+}
+
+func (s *utilsSuite) TestAPIInfoPersistentAddressErrors(c *gc.C) {
+	var tw loggo.TestWriter
+	c.Assert(loggo.RegisterWriter("address-errors-tester", &tw), gc.IsNil)
+	defer loggo.RemoveWriter("address-errors-tester")
+
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0", "i-1", "i-2"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+			"i-1": {id: "i-1", err: errors.New("boom")},
+			"i-2": {id: "i-2"},
+		},
+	}
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070"})
+	c.Check(tw.Log(), jc.LogMatches, []jc.SimpleMessage{
+		{loggo.DEBUG, `failed to get addresses \(ignoring\): instance-id=i-1 error="boom"`},
+		{loggo.WARNING, `no addresses found for controller instances \[i-2\] \(skipping\)`},
+		{loggo.WARNING, `failed to get addresses on every attempt \(skipping\): instance-id=i-1 attempts=\d+ error="boom"`},
+		{loggo.INFO, `skipped 2 of 3 controller instances: not-found=0 no-addresses=1 failed=1`},
+	})
+}
+
+func (s *utilsSuite) TestAPIInfoInstancesError(c *gc.C) {
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+		},
+		instancesErr: errors.New("boom"),
+	}
+	_, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, gc.ErrorMatches, "boom")
+}
+
+func (s *utilsSuite) TestAPIInfoWatchesInstanceStatus(c *gc.C) {
+	inst0 := &mockInstance{id: "i-0", addrs: network.NewAddresses("0.1.2.3")}
+	inst1 := &mockInstance{id: "i-1"}
+	results := make(chan environs.InstancesResult, 2)
+	results <- environs.InstancesResult{Instances: []instance.Instance{inst0, inst1}}
+	results <- environs.InstancesResult{Instances: []instance.Instance{
+		inst0, &mockInstance{id: "i-1", addrs: network.NewAddresses("0.1.2.4")},
+	}}
+	env := &mockWatchingEnviron{
+		mockEnviron: &mockEnviron{
+			controllerInstances: []instance.Id{"i-0", "i-1"},
+		},
+		results: results,
+	}
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070", "0.1.2.4:17070"})
+	c.Assert(env.watchedIds, jc.DeepEquals, []instance.Id{"i-0", "i-1"})
+	c.Assert(env.instancesCalls, gc.Equals, 0)
+	c.Assert(env.watcher.killed, jc.IsTrue)
+}
+
+func (s *utilsSuite) TestAPIInfoWatcherError(c *gc.C) {
+	results := make(chan environs.InstancesResult)
+	close(results)
+	env := &mockWatchingEnviron{
+		mockEnviron: &mockEnviron{
+			controllerInstances: []instance.Id{"i-0"},
+		},
+		results:  results,
+		watchErr: errors.New("boom"),
+	}
+	_, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, gc.ErrorMatches, "watching instance status: boom")
+	c.Assert(env.watcher.killed, jc.IsTrue)
+}
+
+func (s *utilsSuite) TestWaitInstanceAddressesWatchDeadline(c *gc.C) {
+	clock := jujutesting.NewClock(time.Time{})
+	env := &mockWatchingEnviron{
+		mockEnviron: &mockEnviron{},
+		results:     make(chan environs.InstancesResult),
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := environs.WaitAnyInstanceAddresses(
+			context.Background(), clock, env, []instance.Id{"i-0"}, impatientStrategy,
+		)
+		done <- err
+	}()
+
+	select {
+	case <-clock.Alarms():
+	case <-time.After(testing.LongWait):
+		c.Fatalf("timed out waiting for deadline")
+	}
+	clock.Advance(impatientStrategy.Total)
+	select {
+	case err := <-done:
+		c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	case <-time.After(testing.LongWait):
+		c.Fatalf("deadline did not stop the wait")
+	}
+	c.Assert(env.watcher.killed, jc.IsTrue)
+}
+
+func (s *utilsSuite) TestAPIInfoAllControllerAddressesFirstAttempt(c *gc.C) {
+	env := &mockEnviron{
+		controllerInstances: []instance.Id{"i-0", "i-1"},
+		instances: map[instance.Id]*mockInstance{
+			"i-0": {id: "i-0", addrs: network.NewAddresses("0.1.2.3")},
+			"i-1": {id: "i-1", addrs: network.NewAddresses("0.1.2.4")},
+		},
+	}
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, []string{"0.1.2.3:17070", "0.1.2.4:17070"})
+	c.Assert(env.instancesCalls, gc.Equals, 1)
+}
+
+func (s *utilsSuite) TestAPIInfoConcurrentAddressQueries(c *gc.C) {
+	s.PatchValue(&environs.MaxConcurrentAddressQueries, 3)
+
+	var mu sync.Mutex
+	var active, maxActive int
+	hook := func() {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		active--
+		mu.Unlock()
+	}
+
+	env := &mockEnviron{instances: make(map[instance.Id]*mockInstance)}
+	var expectAddrs []string
+	for i := 0; i < 10; i++ {
+		id := instance.Id(fmt.Sprintf("i-%d", i))
+		inst := &mockInstance{id: id, addressesHook: hook}
+		if i == 5 {
+			inst.err = errors.New("boom")
+		} else {
+			addr := fmt.Sprintf("0.1.2.%d", i)
+			inst.addrs = network.NewAddresses(addr)
+			expectAddrs = append(expectAddrs, addr+":17070")
+		}
+		env.controllerInstances = append(env.controllerInstances, id)
+		env.instances[id] = inst
+	}
+
+	info, err := environs.APIInfoWithBackoff(
+		testing.ControllerTag.Id(), testing.ModelTag.Id(), testing.CACert, 17070, env, impatientStrategy,
+	)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(info.Addrs, jc.DeepEquals, expectAddrs)
+	c.Assert(maxActive, jc.LessThan, 4)
+}
+
+// mockEnviron is an environs.Environ that reports a fixed set of
+// controller instances.
+type mockEnviron struct {
+	environs.Environ
+	config              *config.Config
+	controllerInstances []instance.Id
+	instances           map[instance.Id]*mockInstance
+	instancesCalls      int
+	instancesHook       func()
+	instancesErr        error
+
+	controllerInstancesCalls int
+	controllerInstancesHook  func() error
+}
+
+func (e *mockEnviron) Config() *config.Config {
+	if e.config != nil {
+		return e.config
+	}
+	cfg, err := config.New(config.UseDefaults, testing.FakeConfig())
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func (e *mockEnviron) ControllerInstances(string) ([]instance.Id, error) {
+	e.controllerInstancesCalls++
+	if e.controllerInstancesHook != nil {
+		if err := e.controllerInstancesHook(); err != nil {
+			return nil, err
+		}
+	}
+	return e.controllerInstances, nil
+}
+
+func (e *mockEnviron) Instances(ids []instance.Id) ([]instance.Instance, error) {
+	e.instancesCalls++
+	if e.instancesHook != nil {
+		e.instancesHook()
+	}
+	if e.instancesErr != nil {
+		return nil, e.instancesErr
+	}
+	result := make([]instance.Instance, len(ids))
+	var err error
+	for i, id := range ids {
+		if inst, ok := e.instances[id]; ok {
+			result[i] = inst
+		} else {
+			err = environs.ErrPartialInstances
+		}
+	}
+	return result, err
+}
+
+// mockWatchingEnviron is a mockEnviron that reports instance
+// status through a watcher rather than by polling.
+type mockWatchingEnviron struct {
+	*mockEnviron
+	results    chan environs.InstancesResult
+	watchErr   error
+	watchedIds []instance.Id
+	watcher    *mockInstanceStatusWatch
+}
+
+func (e *mockWatchingEnviron) WatchInstanceStatus(clk clock.Clock, ids []instance.Id) (environs.InstanceStatusWatch, error) {
+	e.watchedIds = ids
+	e.watcher = &mockInstanceStatusWatch{changes: e.results, err: e.watchErr}
+	return e.watcher, nil
+}
+
+// mockInstanceStatusWatch is an environs.InstanceStatusWatch which
+// sends the results it is given.
+type mockInstanceStatusWatch struct {
+	changes chan environs.InstancesResult
+	err     error
+	killed  bool
+}
+
+func (w *mockInstanceStatusWatch) Changes() <-chan environs.InstancesResult {
+	return w.changes
+}
+
+func (w *mockInstanceStatusWatch) Kill() {
+	w.killed = true
+}
+
+func (w *mockInstanceStatusWatch) Wait() error {
+	return w.err
+}
+
+type mockInstance struct {
+	instance.Instance
+	id            instance.Id
+	addrs         []network.Address
+	err           error
+	addressesHook func()
+}
+
+func (inst *mockInstance) Id() instance.Id {
+	return inst.id
+}
+
+func (inst *mockInstance) Addresses() ([]network.Address, error) {
+	if inst.addressesHook != nil {
+		inst.addressesHook()
+	}
+	return inst.addrs, inst.err
+}
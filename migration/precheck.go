@@ -5,6 +5,7 @@ package migration
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/juju/version"
@@ -79,23 +80,29 @@ type PrecheckUnit interface {
 // SourcePrecheck checks the state of the source controller to make
 // sure that the preconditions for model migration are met. The
 // backend provided must be for the model to be migrated.
+//
+// All the checks are made even if some fail, so that every problem
+// can be reported at once. If more than one check fails, the error's
+// message lists each failure on its own line. An error reading the
+// state is returned immediately.
 func SourcePrecheck(backend PrecheckBackend) error {
-	if err := checkModel(backend); err != nil {
+	var failures precheckFailures
+	if err := checkModel(backend, &failures); err != nil {
 		return errors.Trace(err)
 	}
 
-	if err := checkMachines(backend); err != nil {
+	if err := checkMachines(backend, &failures); err != nil {
 		return errors.Trace(err)
 	}
 
-	if err := checkApplications(backend); err != nil {
+	if err := checkApplications(backend, &failures); err != nil {
 		return errors.Trace(err)
 	}
 
 	if cleanupNeeded, err := backend.NeedsCleanup(); err != nil {
 		return errors.Annotate(err, "checking cleanups")
 	} else if cleanupNeeded {
-		return errors.New("cleanup needed")
+		failures.addf("cleanup needed")
 	}
 
 	// Check the source controller.
@@ -103,22 +110,26 @@ func SourcePrecheck(backend PrecheckBackend) error {
 	if err != nil {
 		return errors.Trace(err)
 	}
-	if err := checkController(controllerBackend); err != nil {
+	var controllerFailures precheckFailures
+	if err := checkController(controllerBackend, &controllerFailures); err != nil {
 		return errors.Annotate(err, "controller")
 	}
-	return nil
+	for _, failure := range controllerFailures {
+		failures.addf("controller: %s", failure)
+	}
+	return failures.err()
 }
 
-func checkModel(backend PrecheckBackend) error {
+func checkModel(backend PrecheckBackend, failures *precheckFailures) error {
 	model, err := backend.Model()
 	if err != nil {
 		return errors.Annotate(err, "retrieving model")
 	}
 	if model.Life() != state.Alive {
-		return errors.Errorf("model is %s", model.Life())
+		failures.addf("model is %s", model.Life())
 	}
 	if model.MigrationMode() == state.MigrationModeImporting {
-		return errors.New("model is being imported as part of another migration")
+		failures.addf("model is being imported as part of another migration")
 	}
 	return nil
 }
@@ -126,11 +137,16 @@ func checkModel(backend PrecheckBackend) error {
 // TargetPrecheck checks the state of the target controller to make
 // sure that the preconditions for model migration are met. The
 // backend provided must be for the target controller.
+//
+// As with SourcePrecheck, all the checks are made even if some fail,
+// and every failure is reported in the returned error.
 func TargetPrecheck(backend PrecheckBackend, modelInfo coremigration.ModelInfo) error {
 	if err := modelInfo.Validate(); err != nil {
 		return errors.Trace(err)
 	}
 
+	var failures precheckFailures
+
 	// This check is necessary because there is a window between the
 	// REAP phase and then end of the DONE phase where a model's
 	// documents have been deleted but the migration isn't quite done
@@ -141,7 +157,7 @@ func TargetPrecheck(backend PrecheckBackend, modelInfo coremigration.ModelInfo)
 	if migrating, err := backend.IsMigrationActive(modelInfo.UUID); err != nil {
 		return errors.Annotate(err, "checking for active migration")
 	} else if migrating {
-		return errors.New("model is being migrated out of target controller")
+		failures.addf("model is being migrated out of target controller")
 	}
 
 	controllerVersion, err := backend.AgentVersion()
@@ -150,11 +166,11 @@ func TargetPrecheck(backend PrecheckBackend, modelInfo coremigration.ModelInfo)
 	}
 
 	if controllerVersion.Compare(modelInfo.AgentVersion) < 0 {
-		return errors.Errorf("model has higher version than target controller (%s > %s)",
+		failures.addf("model has higher version than target controller (%s > %s)",
 			modelInfo.AgentVersion, controllerVersion)
 	}
 
-	if err := checkController(backend); err != nil {
+	if err := checkController(backend, &failures); err != nil {
 		return errors.Trace(err)
 	}
 
@@ -169,36 +185,39 @@ func TargetPrecheck(backend PrecheckBackend, modelInfo coremigration.ModelInfo)
 		// from a previous migration attempt. It will be removed
 		// before the next import.
 		if model.UUID() == modelInfo.UUID && model.MigrationMode() != state.MigrationModeImporting {
-			return errors.Errorf("model with same UUID already exists (%s)", modelInfo.UUID)
+			failures.addf("model with same UUID already exists (%s)", modelInfo.UUID)
 		}
 		if model.Name() == modelInfo.Name && model.Owner().Canonical() == canonicalOwner {
-			return errors.Errorf("model named %q already exists", model.Name())
+			failures.addf("model named %q already exists", model.Name())
 		}
 	}
 
-	return nil
+	return failures.err()
 }
 
-func checkController(backend PrecheckBackend) error {
+func checkController(backend PrecheckBackend, failures *precheckFailures) error {
 	model, err := backend.Model()
 	if err != nil {
 		return errors.Annotate(err, "retrieving model")
 	}
 	if model.Life() != state.Alive {
-		return errors.Errorf("model is %s", model.Life())
+		failures.addf("model is %s", model.Life())
 	}
 
 	if upgrading, err := backend.IsUpgrading(); err != nil {
 		return errors.Annotate(err, "checking for upgrades")
 	} else if upgrading {
-		return errors.New("upgrade in progress")
+		failures.addf("upgrade in progress")
 	}
 
-	err = checkMachines(backend)
+	err = checkMachines(backend, failures)
 	return errors.Trace(err)
 }
 
-func checkMachines(backend PrecheckBackend) error {
+// checkMachines checks each of the backend's machines in turn. Only
+// the first failed check of each machine is reported, as the later
+// checks are unlikely to be meaningful once one has failed.
+func checkMachines(backend PrecheckBackend, failures *precheckFailures) error {
 	modelVersion, err := backend.AgentVersion()
 	if err != nil {
 		return errors.Annotate(err, "retrieving model version")
@@ -209,37 +228,50 @@ func checkMachines(backend PrecheckBackend) error {
 		return errors.Annotate(err, "retrieving machines")
 	}
 	for _, machine := range machines {
-		if machine.Life() != state.Alive {
-			return errors.Errorf("machine %s is %s", machine.Id(), machine.Life())
+		if err := checkMachine(machine, modelVersion, failures); err != nil {
+			return errors.Trace(err)
 		}
+	}
+	return nil
+}
 
-		if statusInfo, err := machine.InstanceStatus(); err != nil {
-			return errors.Annotatef(err, "retrieving machine %s instance status", machine.Id())
-		} else if statusInfo.Status != status.Running {
-			return newStatusError("machine %s not running", machine.Id(), statusInfo.Status)
-		}
+func checkMachine(machine PrecheckMachine, modelVersion version.Number, failures *precheckFailures) error {
+	if machine.Life() != state.Alive {
+		failures.addf("machine %s is %s", machine.Id(), machine.Life())
+		return nil
+	}
 
-		if statusInfo, err := common.MachineStatus(machine); err != nil {
-			return errors.Annotatef(err, "retrieving machine %s status", machine.Id())
-		} else if statusInfo.Status != status.Started {
-			return newStatusError("machine %s agent not functioning at this time",
-				machine.Id(), statusInfo.Status)
-		}
+	if statusInfo, err := machine.InstanceStatus(); err != nil {
+		return errors.Annotatef(err, "retrieving machine %s instance status", machine.Id())
+	} else if statusInfo.Status != status.Running {
+		failures.add(statusFailure("machine %s not running", machine.Id(), statusInfo.Status))
+		return nil
+	}
 
-		if rebootAction, err := machine.ShouldRebootOrShutdown(); err != nil {
-			return errors.Annotatef(err, "retrieving machine %s reboot status", machine.Id())
-		} else if rebootAction != state.ShouldDoNothing {
-			return errors.Errorf("machine %s is scheduled to %s", machine.Id(), rebootAction)
-		}
+	if statusInfo, err := common.MachineStatus(machine); err != nil {
+		return errors.Annotatef(err, "retrieving machine %s status", machine.Id())
+	} else if statusInfo.Status != status.Started {
+		failures.add(statusFailure("machine %s agent not functioning at this time",
+			machine.Id(), statusInfo.Status))
+		return nil
+	}
 
-		if err := checkAgentTools(modelVersion, machine, "machine "+machine.Id()); err != nil {
-			return errors.Trace(err)
-		}
+	if rebootAction, err := machine.ShouldRebootOrShutdown(); err != nil {
+		return errors.Annotatef(err, "retrieving machine %s reboot status", machine.Id())
+	} else if rebootAction != state.ShouldDoNothing {
+		failures.addf("machine %s is scheduled to %s", machine.Id(), rebootAction)
+		return nil
+	}
+
+	if failure, err := checkAgentTools(modelVersion, machine, "machine "+machine.Id()); err != nil {
+		return errors.Trace(err)
+	} else if failure != "" {
+		failures.add(failure)
 	}
 	return nil
 }
 
-func checkApplications(backend PrecheckBackend) error {
+func checkApplications(backend PrecheckBackend, failures *precheckFailures) error {
 	modelVersion, err := backend.AgentVersion()
 	if err != nil {
 		return errors.Annotate(err, "retrieving model version")
@@ -250,9 +282,10 @@ func checkApplications(backend PrecheckBackend) error {
 	}
 	for _, app := range apps {
 		if app.Life() != state.Alive {
-			return errors.Errorf("application %s is %s", app.Name(), app.Life())
+			failures.addf("application %s is %s", app.Name(), app.Life())
+			continue
 		}
-		err := checkUnits(app, modelVersion)
+		err := checkUnits(app, modelVersion, failures)
 		if err != nil {
 			return errors.Trace(err)
 		}
@@ -260,71 +293,115 @@ func checkApplications(backend PrecheckBackend) error {
 	return nil
 }
 
-func checkUnits(app PrecheckApplication, modelVersion version.Number) error {
+func checkUnits(app PrecheckApplication, modelVersion version.Number, failures *precheckFailures) error {
 	units, err := app.AllUnits()
 	if err != nil {
 		return errors.Annotatef(err, "retrieving units for %s", app.Name())
 	}
 	if len(units) < app.MinUnits() {
-		return errors.Errorf("application %s is below its minimum units threshold", app.Name())
+		failures.addf("application %s is below its minimum units threshold", app.Name())
 	}
 
 	appCharmURL, _ := app.CharmURL()
 
 	for _, unit := range units {
-		if unit.Life() != state.Alive {
-			return errors.Errorf("unit %s is %s", unit.Name(), unit.Life())
-		}
-
-		if err := checkUnitAgentStatus(unit); err != nil {
+		if err := checkUnit(unit, appCharmURL, modelVersion, failures); err != nil {
 			return errors.Trace(err)
 		}
+	}
+	return nil
+}
 
-		if err := checkAgentTools(modelVersion, unit, "unit "+unit.Name()); err != nil {
-			return errors.Trace(err)
-		}
+// checkUnit checks the given unit. As with machines, only the first
+// failed check of each unit is reported.
+func checkUnit(unit PrecheckUnit, appCharmURL *charm.URL, modelVersion version.Number, failures *precheckFailures) error {
+	if unit.Life() != state.Alive {
+		failures.addf("unit %s is %s", unit.Name(), unit.Life())
+		return nil
+	}
 
-		unitCharmURL, _ := unit.CharmURL()
-		if appCharmURL.String() != unitCharmURL.String() {
-			return errors.Errorf("unit %s is upgrading", unit.Name())
-		}
+	if failure, err := checkUnitAgentStatus(unit); err != nil {
+		return errors.Trace(err)
+	} else if failure != "" {
+		failures.add(failure)
+		return nil
+	}
+
+	if failure, err := checkAgentTools(modelVersion, unit, "unit "+unit.Name()); err != nil {
+		return errors.Trace(err)
+	} else if failure != "" {
+		failures.add(failure)
+		return nil
+	}
+
+	unitCharmURL, _ := unit.CharmURL()
+	if appCharmURL.String() != unitCharmURL.String() {
+		failures.addf("unit %s is upgrading", unit.Name())
 	}
 	return nil
 }
 
-func checkUnitAgentStatus(unit PrecheckUnit) error {
+// checkUnitAgentStatus returns a description of the failure if the
+// unit's agent is not idle, or the empty string if it is.
+func checkUnitAgentStatus(unit PrecheckUnit) (string, error) {
 	statusData, _ := common.UnitStatus(unit)
 	if statusData.Err != nil {
-		return errors.Annotatef(statusData.Err, "retrieving unit %s status", unit.Name())
+		return "", errors.Annotatef(statusData.Err, "retrieving unit %s status", unit.Name())
 	}
 	agentStatus := statusData.Status.Status
 	if agentStatus != status.Idle {
-		return newStatusError("unit %s not idle", unit.Name(), agentStatus)
+		return statusFailure("unit %s not idle", unit.Name(), agentStatus), nil
 	}
-	return nil
+	return "", nil
 }
 
-func checkAgentTools(modelVersion version.Number, agent agentToolsGetter, agentLabel string) error {
+// checkAgentTools returns a description of the failure if the agent's
+// tools don't match the model version, or the empty string if they do.
+func checkAgentTools(modelVersion version.Number, agent agentToolsGetter, agentLabel string) (string, error) {
 	tools, err := agent.AgentTools()
 	if err != nil {
-		return errors.Annotatef(err, "retrieving tools for %s", agentLabel)
+		return "", errors.Annotatef(err, "retrieving tools for %s", agentLabel)
 	}
 	agentVersion := tools.Version.Number
 	if agentVersion != modelVersion {
-		return errors.Errorf("%s tools don't match model (%s != %s)",
-			agentLabel, agentVersion, modelVersion)
+		return fmt.Sprintf("%s tools don't match model (%s != %s)",
+			agentLabel, agentVersion, modelVersion), nil
 	}
-	return nil
+	return "", nil
 }
 
 type agentToolsGetter interface {
 	AgentTools() (*tools.Tools, error)
 }
 
-func newStatusError(format, id string, s status.Status) error {
+func statusFailure(format, id string, s status.Status) string {
 	msg := fmt.Sprintf(format, id)
 	if s != status.Empty {
 		msg += fmt.Sprintf(" (%s)", s)
 	}
-	return errors.New(msg)
+	return msg
+}
+
+// precheckFailures collects the reasons that prechecks failed, so
+// that they can be reported together.
+type precheckFailures []string
+
+func (f *precheckFailures) add(failure string) {
+	*f = append(*f, failure)
+}
+
+func (f *precheckFailures) addf(format string, args ...interface{}) {
+	*f = append(*f, fmt.Sprintf(format, args...))
+}
+
+// err returns nil if no failures were collected. Otherwise it returns
+// an error describing them, one per line if there is more than one.
+func (f precheckFailures) err() error {
+	switch len(f) {
+	case 0:
+		return nil
+	case 1:
+		return errors.New(f[0])
+	}
+	return errors.Errorf("%d prechecks failed:\n- %s", len(f), strings.Join(f, "\n- "))
 }
@@ -44,6 +44,21 @@ func (*SourcePrecheckSuite) TestSuccess(c *gc.C) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+func (*SourcePrecheckSuite) TestMultipleFailures(c *gc.C) {
+	backend := newBackendWithDyingMachine()
+	backend.cleanupNeeded = true
+	backend.apps = []migration.PrecheckApplication{
+		&fakeApp{name: "foo", life: state.Dying},
+	}
+	backend.controllerBackend.isUpgrading = true
+	err := migration.SourcePrecheck(backend)
+	c.Assert(err.Error(), gc.Equals, `4 prechecks failed:
+- machine 0 is dying
+- application foo is dying
+- cleanup needed
+- controller: upgrade in progress`)
+}
+
 func (*SourcePrecheckSuite) TestDyingModel(c *gc.C) {
 	backend := newFakeBackend()
 	backend.model.life = state.Dying
@@ -315,6 +330,20 @@ func (s *TargetPrecheckSuite) TestVersionLessThanSource(c *gc.C) {
 		`model has higher version than target controller (1.2.4 > 1.2.3)`)
 }
 
+func (s *TargetPrecheckSuite) TestMultipleFailures(c *gc.C) {
+	backend := newBackendWithDownMachine()
+	backend.models = []migration.PrecheckModel{
+		&fakeModel{uuid: modelUUID, name: modelName, owner: modelOwner},
+	}
+	s.modelInfo.AgentVersion = version.MustParse("1.2.4")
+	err := migration.TargetPrecheck(backend, s.modelInfo)
+	c.Assert(err.Error(), gc.Equals, `4 prechecks failed:
+- model has higher version than target controller (1.2.4 > 1.2.3)
+- machine 0 agent not functioning at this time (down)
+- model with same UUID already exists (model-uuid)
+- model named "model-name" already exists`)
+}
+
 func (s *TargetPrecheckSuite) TestDying(c *gc.C) {
 	backend := newFakeBackend()
 	backend.model.life = state.Dying
@@ -476,6 +505,7 @@ func newHappyBackend() *fakeBackend {
 
 func newBackendWithMismatchingTools() *fakeBackend {
 	return &fakeBackend{
+		controllerBackend: &fakeBackend{},
 		machines: []migration.PrecheckMachine{
 			&fakeMachine{id: "0"},
 			&fakeMachine{id: "1", version: version.MustParseBinary("1.3.1-xenial-amd64")},
@@ -485,6 +515,7 @@ func newBackendWithMismatchingTools() *fakeBackend {
 
 func newBackendWithRebootingMachine() *fakeBackend {
 	return &fakeBackend{
+		controllerBackend: &fakeBackend{},
 		machines: []migration.PrecheckMachine{
 			&fakeMachine{id: "0", rebootAction: state.ShouldReboot},
 		},
@@ -493,6 +524,7 @@ func newBackendWithRebootingMachine() *fakeBackend {
 
 func newBackendWithDyingMachine() *fakeBackend {
 	return &fakeBackend{
+		controllerBackend: &fakeBackend{},
 		machines: []migration.PrecheckMachine{
 			&fakeMachine{id: "0", life: state.Dying},
 			&fakeMachine{id: "1"},
@@ -502,6 +534,7 @@ func newBackendWithDyingMachine() *fakeBackend {
 
 func newBackendWithDownMachine() *fakeBackend {
 	return &fakeBackend{
+		controllerBackend: &fakeBackend{},
 		machines: []migration.PrecheckMachine{
 			&fakeMachine{id: "0", status: status.Down},
 			&fakeMachine{id: "1"},
@@ -511,6 +544,7 @@ func newBackendWithDownMachine() *fakeBackend {
 
 func newBackendWithProvisioningMachine() *fakeBackend {
 	return &fakeBackend{
+		controllerBackend: &fakeBackend{},
 		machines: []migration.PrecheckMachine{
 			&fakeMachine{id: "0", instanceStatus: status.Provisioning},
 			&fakeMachine{id: "1"},
@@ -520,6 +554,7 @@ func newBackendWithProvisioningMachine() *fakeBackend {
 
 func newBackendWithDownMachineAgent() *fakeBackend {
 	return &fakeBackend{
+		controllerBackend: &fakeBackend{},
 		machines: []migration.PrecheckMachine{
 			&fakeMachine{id: "0"},
 			&fakeMachine{id: "1", lost: true},
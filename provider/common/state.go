@@ -61,7 +61,9 @@ func SaveState(storage storage.StorageWriter, state *BootstrapState) error {
 	return putState(storage, data)
 }
 
-// LoadState reads state from the given storage.
+// LoadState reads state from the given storage. If there is no state
+// file, an error satisfying environs.IsNotBootstrapped is returned;
+// any other error from the storage is returned as is.
 func LoadState(stor storage.StorageReader) (*BootstrapState, error) {
 	r, err := storage.Get(stor, StateFile)
 	if err != nil {
@@ -91,7 +93,7 @@ func loadState(r io.ReadCloser) (*BootstrapState, error) {
 // file in storage.
 func AddStateInstance(stor storage.Storage, id instance.Id) error {
 	state, err := LoadState(stor)
-	if err == environs.ErrNotBootstrapped {
+	if environs.IsNotBootstrapped(err) {
 		state = &BootstrapState{}
 	} else if err != nil {
 		return errors.Annotate(err, "cannot record state instance-id")
@@ -105,7 +107,7 @@ func AddStateInstance(stor storage.Storage, id instance.Id) error {
 // in the file are ignored.
 func RemoveStateInstances(stor storage.Storage, ids ...instance.Id) error {
 	state, err := LoadState(stor)
-	if err == environs.ErrNotBootstrapped {
+	if environs.IsNotBootstrapped(err) {
 		return nil
 	} else if err != nil {
 		return errors.Annotate(err, "cannot remove recorded state instance-id")
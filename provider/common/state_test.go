@@ -4,12 +4,14 @@
 package common_test
 
 import (
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	gc "gopkg.in/check.v1"
 	goyaml "gopkg.in/yaml.v2"
@@ -127,6 +129,28 @@ func (suite *StateSuite) TestLoadStateMissingFile(c *gc.C) {
 	stor := suite.newStorage(c)
 	_, err := common.LoadState(stor)
 	c.Check(err, gc.Equals, environs.ErrNotBootstrapped)
+	c.Check(err, jc.Satisfies, environs.IsNotBootstrapped)
+}
+
+func (suite *StateSuite) TestLoadStateStorageError(c *gc.C) {
+	stor := &errorStorage{
+		StorageReader: suite.newStorage(c),
+		err:           errors.New("access denied"),
+	}
+	_, err := common.LoadState(stor)
+	c.Check(err, gc.ErrorMatches, "access denied")
+	c.Check(err, gc.Not(jc.Satisfies), environs.IsNotBootstrapped)
+}
+
+// errorStorage is a storage.StorageReader whose Get
+// method always fails with the configured error.
+type errorStorage struct {
+	storage.StorageReader
+	err error
+}
+
+func (s *errorStorage) Get(name string) (io.ReadCloser, error) {
+	return nil, s.err
 }
 
 func (suite *StateSuite) TestLoadStateIntegratesWithSaveState(c *gc.C) {
@@ -0,0 +1,145 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/ssh"
+	"gopkg.in/amz.v3/ec2"
+	"gopkg.in/juju/names.v2"
+
+	"github.com/juju/juju/cloudconfig/instancecfg"
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/environs/manual"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/provider/common"
+)
+
+// adoptPlacementPrefix prefixes the bootstrap placement directive
+// naming an existing instance to adopt as the controller.
+const adoptPlacementPrefix = "instance-id="
+
+var (
+	checkProvisioned                       = manual.CheckProvisioned
+	detectSeriesAndHardwareCharacteristics = manual.DetectSeriesAndHardwareCharacteristics
+	configureMachine                       = common.ConfigureMachine
+)
+
+// validInstanceId matches the ids EC2 gives to instances.
+var validInstanceId = regexp.MustCompile("^i-[0-9a-f]+$")
+
+// adoptedInstanceId returns the id of the instance named by an
+// "instance-id=<id>" bootstrap placement directive, and whether
+// the placement is such a directive. An error is returned if the
+// directive does not name a valid instance id.
+func adoptedInstanceId(placement string) (instance.Id, bool, error) {
+	if !strings.HasPrefix(placement, adoptPlacementPrefix) {
+		return "", false, nil
+	}
+	id := strings.TrimPrefix(placement, adoptPlacementPrefix)
+	if !validInstanceId.MatchString(id) {
+		return "", true, errors.NotValidf("instance id %q in placement %q", id, placement)
+	}
+	return instance.Id(id), true, nil
+}
+
+// adoptBootstrapInstance bootstraps by adopting an existing instance
+// as the controller, rather than starting a new one. The instance must
+// be running, have a public address, accept SSH connections as the
+// "ubuntu" user with the client's keys, and not already have juju
+// installed. It is tagged as a controller instance, so it is found by
+// ControllerInstances and terminated when the controller is destroyed,
+// and the controller cloud-config is run on it over SSH.
+//
+// The instance keeps its existing security groups, which must allow
+// access to the API server port.
+func (e *environ) adoptBootstrapInstance(
+	ctx environs.BootstrapContext, args environs.BootstrapParams, id instance.Id,
+) (*environs.BootstrapResult, error) {
+	insts, err := e.Instances([]instance.Id{id})
+	if err == environs.ErrNoInstances {
+		return nil, errors.NotFoundf("instance %q", id)
+	} else if err != nil {
+		return nil, errors.Trace(err)
+	}
+	inst := insts[0].(*ec2Instance)
+	if state := inst.Instance.State.Name; state != "running" {
+		return nil, errors.Errorf("cannot adopt instance %q: instance is %s, not running", id, state)
+	}
+	if err := e.checkAdoptableNetwork(inst.Instance); err != nil {
+		return nil, errors.Annotatef(err, "cannot adopt instance %q", id)
+	}
+	addrs, err := inst.Addresses()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	addr, ok := network.SelectPublicAddress(addrs)
+	if !ok {
+		return nil, errors.Errorf("cannot adopt instance %q: instance has no public address", id)
+	}
+	host := addr.Value
+
+	provisioned, err := checkProvisioned(host)
+	if err != nil {
+		return nil, errors.Annotatef(err, "cannot reach instance %q at %s", id, host)
+	}
+	if provisioned {
+		return nil, errors.Annotatef(manual.ErrProvisioned, "cannot adopt instance %q", id)
+	}
+	hc, series, err := detectSeriesAndHardwareCharacteristics(host)
+	if err != nil {
+		return nil, errors.Annotatef(err, "detecting series and hardware of instance %q", id)
+	}
+	if hc.Arch == nil {
+		return nil, errors.Errorf("cannot adopt instance %q: cannot detect its architecture", id)
+	}
+	ctx.Infof("Adopting instance %s at %s", id, host)
+
+	finalize := func(ctx environs.BootstrapContext, icfg *instancecfg.InstanceConfig, _ environs.BootstrapDialOpts) error {
+		icfg.Bootstrap.BootstrapMachineInstanceId = id
+		icfg.Bootstrap.BootstrapMachineHardwareCharacteristics = &hc
+		cfg := e.Config()
+		if err := instancecfg.FinishInstanceConfig(icfg, cfg); err != nil {
+			return errors.Trace(err)
+		}
+		instanceTags := instancecfg.InstanceTags(
+			cfg.UUID(), args.ControllerConfig.ControllerUUID(), cfg, icfg.Jobs,
+		)
+		instanceTags[tagName] = resourceName(names.NewMachineTag(icfg.MachineId), cfg.Name())
+		if err := tagResources(e.ec2, instanceTags, string(id)); err != nil {
+			return errors.Annotate(err, "tagging instance")
+		}
+		return configureMachine(ctx, ssh.DefaultClient, host, icfg)
+	}
+	return &environs.BootstrapResult{
+		Arch:     *hc.Arch,
+		Series:   series,
+		Finalize: finalize,
+	}, nil
+}
+
+// checkAdoptableNetwork returns an error if inst is not in the model's
+// VPC, when vpc-id is set, or not in the model's subnet, and so its
+// availability zone, when subnet-id is set.
+func (e *environ) checkAdoptableNetwork(inst *ec2.Instance) error {
+	if vpcID := e.ecfg().vpcID(); isVPCIDSet(vpcID) && inst.VPCId != vpcID {
+		return errors.Errorf("instance is in VPC %q, not the model's VPC %q", inst.VPCId, vpcID)
+	}
+	subnetID := e.ecfg().subnetID()
+	if subnetID == "" || inst.SubnetId == subnetID {
+		return nil
+	}
+	subnet, err := getVPCSubnet(e.ec2, e.ecfg().vpcID(), subnetID)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return errors.Errorf(
+		"instance is in subnet %q in availability zone %q, not the model's subnet %q in availability zone %q",
+		inst.SubnetId, inst.AvailZone, subnetID, subnet.AvailZone,
+	)
+}
@@ -5,7 +5,12 @@ package ec2
 
 import (
 	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/juju/errors"
 	"github.com/juju/schema"
 	"gopkg.in/juju/environschema.v1"
 
@@ -26,6 +31,92 @@ var configSchema = environschema.Fields{
 		Group:       environschema.AccountGroup,
 		Immutable:   true,
 	},
+	"subnet-id": {
+		Description: "Launch all instances into a specific subnet of the AWS VPC given with vpc-id (optional). Not accepted without vpc-id.",
+		Example:     "subnet-a1b2c3d4",
+		Type:        environschema.Tstring,
+		Group:       environschema.AccountGroup,
+		Immutable:   true,
+	},
+	"ec2-endpoint": {
+		Description: "The URL of the EC2 endpoint to use instead of the region's default (optional), such as a FIPS or VPC endpoint.",
+		Example:     "https://ec2-fips.us-east-1.amazonaws.com",
+		Type:        environschema.Tstring,
+		Group:       environschema.AccountGroup,
+		Immutable:   true,
+	},
+	"s3-endpoint": {
+		Description: "The URL of the S3 endpoint to use instead of the region's default (optional), such as a FIPS or VPC endpoint.",
+		Example:     "https://s3-fips.us-east-1.amazonaws.com",
+		Type:        environschema.Tstring,
+		Group:       environschema.AccountGroup,
+		Immutable:   true,
+	},
+	"root-disk": {
+		Description: "The size, in MiB, of the root EBS volume for machines which have no root-disk constraint (optional). It must be at least as large as the image's root volume. Controllers can be given a different size with the root-disk bootstrap constraint.",
+		Example:     "16384",
+		Type:        environschema.Tint,
+		Group:       environschema.EnvironGroup,
+	},
+	"iam-instance-profile": {
+		Description: "The name or ARN of an IAM instance profile to associate with machines (optional), allowing workloads to call AWS APIs without credentials.",
+		Example:     "juju-machine",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"controller-iam-instance-profile": {
+		Description: "The name or ARN of an IAM instance profile to associate with controller machines (optional). When not specified, iam-instance-profile is used.",
+		Example:     "juju-controller",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"cloudinit-userdata": {
+		Description: "A YAML cloud-config fragment to merge into the cloud-init user data of Ubuntu and CentOS machines (optional), such as apt mirrors or extra SSH keys. Lists such as runcmd are appended to juju's own; other keys that juju sets are ignored.",
+		Example:     "runcmd:\n  - echo hello",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"extra-security-groups": {
+		Description: "A comma-separated list of the IDs or names of existing security groups to attach to all machines, in addition to the groups juju manages (optional). The groups must exist in the model's region, and in its VPC if vpc-id is specified.",
+		Example:     "sg-a1b2c3d4,monitoring",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"terminate-timeout": {
+		Description: "How long destroying the model waits for its instances to reach the terminated state, as a duration such as \"5m\" (optional). When not specified, juju does not wait. If any instances have not terminated in time, destroying the model fails, naming them.",
+		Example:     "5m",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"spot-price": {
+		Description: "The maximum hourly price, in US dollars, to bid for spot instances (optional). When specified, non-controller machines are provisioned as spot instances rather than on-demand instances.",
+		Example:     "0.05",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"placement-group": {
+		Description: "The name of an existing EC2 placement group to launch all machines into (optional), such as a cluster placement group for low-latency networking. Not accepted with spot-price.",
+		Example:     "hpc-cluster",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"root-device-type": {
+		Description: "The type of root device of the images machines are started from, either \"ebs\" or \"instance-store\" (optional). Machines with an instance-store root device incur no EBS charges for it, but lose their root disk when stopped.",
+		Example:     "instance-store",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"virt-type": {
+		Description: "The virtualization type of the images machines are started from, either \"hvm\", \"pv\" or \"auto\" (optional). With \"auto\", the type is chosen to suit the instance type.",
+		Example:     "hvm",
+		Type:        environschema.Tstring,
+		Group:       environschema.EnvironGroup,
+	},
+	"encrypted-storage": {
+		Description: "Whether all EBS volumes that juju creates are encrypted, whatever the encrypted attribute of their storage pool (optional).",
+		Type:        environschema.Tbool,
+		Group:       environschema.EnvironGroup,
+	},
 }
 
 var configFields = func() schema.Fields {
@@ -39,6 +130,22 @@ var configFields = func() schema.Fields {
 var configDefaults = schema.Defaults{
 	"vpc-id":       "",
 	"vpc-id-force": false,
+	"subnet-id":    "",
+	"ec2-endpoint": "",
+	"s3-endpoint":  "",
+	"root-disk":    0,
+	"spot-price":   "",
+
+	"placement-group":   "",
+	"root-device-type":  rootDeviceTypeEBS,
+	"virt-type":         virtTypeAuto,
+	"encrypted-storage": false,
+
+	"cloudinit-userdata":              "",
+	"extra-security-groups":           "",
+	"iam-instance-profile":            "",
+	"controller-iam-instance-profile": "",
+	"terminate-timeout":               "",
 }
 
 type environConfig struct {
@@ -54,6 +161,78 @@ func (c *environConfig) forceVPCID() bool {
 	return c.attrs["vpc-id-force"].(bool)
 }
 
+func (c *environConfig) subnetID() string {
+	return c.attrs["subnet-id"].(string)
+}
+
+func (c *environConfig) ec2Endpoint() string {
+	return c.attrs["ec2-endpoint"].(string)
+}
+
+func (c *environConfig) s3Endpoint() string {
+	return c.attrs["s3-endpoint"].(string)
+}
+
+func (c *environConfig) rootDisk() int {
+	return c.attrs["root-disk"].(int)
+}
+
+func (c *environConfig) iamInstanceProfile() string {
+	return c.attrs["iam-instance-profile"].(string)
+}
+
+func (c *environConfig) controllerIAMInstanceProfile() string {
+	return c.attrs["controller-iam-instance-profile"].(string)
+}
+
+func (c *environConfig) cloudInitUserData() string {
+	return c.attrs["cloudinit-userdata"].(string)
+}
+
+// extraSecurityGroups returns the IDs or names of the security
+// groups listed in the extra-security-groups attribute.
+func (c *environConfig) extraSecurityGroups() []string {
+	var groups []string
+	for _, group := range strings.Split(c.attrs["extra-security-groups"].(string), ",") {
+		if group = strings.TrimSpace(group); group != "" {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+func (c *environConfig) spotPrice() string {
+	return c.attrs["spot-price"].(string)
+}
+
+func (c *environConfig) placementGroup() string {
+	return c.attrs["placement-group"].(string)
+}
+
+func (c *environConfig) rootDeviceType() string {
+	return c.attrs["root-device-type"].(string)
+}
+
+func (c *environConfig) virtType() string {
+	return c.attrs["virt-type"].(string)
+}
+
+func (c *environConfig) encryptedStorage() bool {
+	return c.attrs["encrypted-storage"].(bool)
+}
+
+// terminateTimeout returns the duration given by the terminate-timeout
+// attribute, or zero if it is not set.
+func (c *environConfig) terminateTimeout() time.Duration {
+	timeout, err := time.ParseDuration(c.attrs["terminate-timeout"].(string))
+	if err != nil {
+		// Only an empty value fails here, as the
+		// attribute has been validated.
+		return 0
+	}
+	return timeout
+}
+
 func (p environProvider) newConfig(cfg *config.Config) (*environConfig, error) {
 	valid, err := p.Validate(cfg, nil)
 	if err != nil {
@@ -100,6 +279,89 @@ func validateConfig(cfg, old *config.Config) (*environConfig, error) {
 		return nil, fmt.Errorf("cannot use vpc-id-force without specifying vpc-id as well")
 	}
 
+	if subnetID := ecfg.subnetID(); subnetID != "" {
+		if !strings.HasPrefix(subnetID, "subnet-") {
+			return nil, fmt.Errorf("subnet-id: %q is not a valid AWS subnet ID", subnetID)
+		}
+		if !isVPCIDSet(ecfg.vpcID()) {
+			return nil, fmt.Errorf("cannot use subnet-id without specifying vpc-id as well")
+		}
+	}
+
+	for _, key := range []string{"ec2-endpoint", "s3-endpoint"} {
+		if endpoint := ecfg.attrs[key].(string); endpoint != "" {
+			if u, err := url.Parse(endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("%s: %q is not a valid URL", key, endpoint)
+			}
+		}
+	}
+	if (ecfg.ec2Endpoint() == "") != (ecfg.s3Endpoint() == "") {
+		logger.Warningf("only one of ec2-endpoint and s3-endpoint is set; the other uses the region's default")
+	}
+
+	if rootDisk := ecfg.rootDisk(); rootDisk < 0 {
+		return nil, fmt.Errorf("root-disk: %d is not a valid size", rootDisk)
+	}
+
+	for _, key := range []string{"iam-instance-profile", "controller-iam-instance-profile"} {
+		if profile := ecfg.attrs[key].(string); profile != "" && strings.TrimSpace(profile) == "" {
+			return nil, fmt.Errorf("%s: %q is not a valid instance profile", key, profile)
+		}
+	}
+
+	if userData := ecfg.cloudInitUserData(); userData != "" {
+		if _, err := parseCloudInitUserData(userData); err != nil {
+			return nil, fmt.Errorf("cloudinit-userdata: invalid YAML: %v", errors.Cause(err))
+		}
+	}
+
+	if groups := ecfg.attrs["extra-security-groups"].(string); groups != "" {
+		for _, group := range strings.Split(groups, ",") {
+			if strings.TrimSpace(group) == "" {
+				return nil, fmt.Errorf("extra-security-groups: %q contains an empty group", groups)
+			}
+		}
+	}
+
+	if spotPrice := ecfg.spotPrice(); spotPrice != "" {
+		if price, err := strconv.ParseFloat(spotPrice, 64); err != nil || price <= 0 {
+			return nil, fmt.Errorf("spot-price: %q is not a valid price", spotPrice)
+		}
+	}
+
+	if group := ecfg.placementGroup(); group != "" {
+		if strings.TrimSpace(group) == "" {
+			return nil, fmt.Errorf("placement-group: %q is not a valid placement group name", group)
+		}
+		if ecfg.spotPrice() != "" {
+			return nil, fmt.Errorf("cannot use placement-group with spot-price")
+		}
+	}
+
+	switch rootDeviceType := ecfg.rootDeviceType(); rootDeviceType {
+	case rootDeviceTypeEBS, rootDeviceTypeInstanceStore:
+	default:
+		return nil, fmt.Errorf(
+			"root-device-type: %q is not valid (expected %q or %q)",
+			rootDeviceType, rootDeviceTypeEBS, rootDeviceTypeInstanceStore,
+		)
+	}
+
+	switch virtType := ecfg.virtType(); virtType {
+	case virtTypeAuto, hvm, paravirtual:
+	default:
+		return nil, fmt.Errorf(
+			"virt-type: %q is not valid (expected %q, %q or %q)",
+			virtType, hvm, paravirtual, virtTypeAuto,
+		)
+	}
+
+	if timeout := ecfg.attrs["terminate-timeout"].(string); timeout != "" {
+		if d, err := time.ParseDuration(timeout); err != nil || d < 0 {
+			return nil, fmt.Errorf("terminate-timeout: %q is not a valid duration", timeout)
+		}
+	}
+
 	if old != nil {
 		attrs := old.UnknownAttrs()
 
@@ -110,6 +372,16 @@ func validateConfig(cfg, old *config.Config) (*environConfig, error) {
 		if forceVPCID, _ := attrs["vpc-id-force"].(bool); forceVPCID != ecfg.forceVPCID() {
 			return nil, fmt.Errorf("cannot change vpc-id-force from %v to %v", forceVPCID, ecfg.forceVPCID())
 		}
+
+		if subnetID, _ := attrs["subnet-id"].(string); subnetID != ecfg.subnetID() {
+			return nil, fmt.Errorf("cannot change subnet-id from %q to %q", subnetID, ecfg.subnetID())
+		}
+
+		for _, key := range []string{"ec2-endpoint", "s3-endpoint"} {
+			if endpoint, _ := attrs[key].(string); endpoint != ecfg.attrs[key].(string) {
+				return nil, fmt.Errorf("cannot change %s from %q to %q", key, endpoint, ecfg.attrs[key])
+			}
+		}
 	}
 
 	// ssl-hostname-verification cannot be disabled
@@ -279,6 +279,214 @@ var configTests = []configTest{
 			"ssl-hostname-verification": false,
 		},
 		err: ".*disabling ssh-hostname-verification is not supported",
+	}, {
+		config: attrs{
+			"extra-security-groups": "sg-1234, monitoring",
+		},
+		expect: attrs{
+			"extra-security-groups": "sg-1234, monitoring",
+		},
+	}, {
+		config: attrs{
+			"extra-security-groups": "sg-1234,,monitoring",
+		},
+		err: `.*extra-security-groups: "sg-1234,,monitoring" contains an empty group`,
+	}, {
+		config: attrs{
+			"terminate-timeout": "5m",
+		},
+		expect: attrs{
+			"terminate-timeout": "5m",
+		},
+	}, {
+		config: attrs{
+			"terminate-timeout": "soon",
+		},
+		err: `.*terminate-timeout: "soon" is not a valid duration`,
+	}, {
+		config: attrs{
+			"terminate-timeout": "-1s",
+		},
+		err: `.*terminate-timeout: "-1s" is not a valid duration`,
+	}, {
+		config: attrs{
+			"spot-price": "0.05",
+		},
+		expect: attrs{
+			"spot-price": "0.05",
+		},
+	}, {
+		config: attrs{
+			"encrypted-storage": true,
+		},
+		expect: attrs{
+			"encrypted-storage": true,
+		},
+	}, {
+		config: attrs{
+			"placement-group": "hpc-cluster",
+		},
+		expect: attrs{
+			"placement-group": "hpc-cluster",
+		},
+	}, {
+		config: attrs{
+			"placement-group": " ",
+		},
+		err: `.*placement-group: " " is not a valid placement group name`,
+	}, {
+		config: attrs{
+			"placement-group": "hpc-cluster",
+			"spot-price":      "0.05",
+		},
+		err: `.*cannot use placement-group with spot-price`,
+	}, {
+		config: attrs{
+			"root-device-type": "instance-store",
+		},
+		expect: attrs{
+			"root-device-type": "instance-store",
+		},
+	}, {
+		config: attrs{
+			"root-device-type": "magnetic",
+		},
+		err: `.*root-device-type: "magnetic" is not valid \(expected "ebs" or "instance-store"\)`,
+	}, {
+		config: attrs{
+			"spot-price": "cheap",
+		},
+		err: `.*spot-price: "cheap" is not a valid price`,
+	}, {
+		config: attrs{
+			"spot-price": "-1",
+		},
+		err: `.*spot-price: "-1" is not a valid price`,
+	}, {
+		config: attrs{
+			"root-disk": 16384,
+		},
+		expect: attrs{
+			"root-disk": 16384,
+		},
+	}, {
+		config: attrs{
+			"root-disk": -1,
+		},
+		err: `.*root-disk: -1 is not a valid size`,
+	}, {
+		config: attrs{
+			"iam-instance-profile":            "juju-machine",
+			"controller-iam-instance-profile": "arn:aws:iam::123456789012:instance-profile/juju-controller",
+		},
+		expect: attrs{
+			"iam-instance-profile":            "juju-machine",
+			"controller-iam-instance-profile": "arn:aws:iam::123456789012:instance-profile/juju-controller",
+		},
+	}, {
+		config: attrs{
+			"iam-instance-profile": " ",
+		},
+		err: `.*iam-instance-profile: " " is not a valid instance profile`,
+	}, {
+		config: attrs{
+			"cloudinit-userdata": "runcmd:\n  - echo hello\n",
+		},
+		expect: attrs{
+			"cloudinit-userdata": "runcmd:\n  - echo hello\n",
+		},
+	}, {
+		config: attrs{
+			"cloudinit-userdata": "runcmd: [",
+		},
+		err: `.*cloudinit-userdata: invalid YAML: .*`,
+	}, {
+		config: attrs{
+			"cloudinit-userdata": "- echo hello",
+		},
+		err: `.*cloudinit-userdata: invalid YAML: .*`,
+	}, {
+		config: attrs{
+			"vpc-id":    "vpc-abcd",
+			"subnet-id": "subnet-a1b2c3d4",
+		},
+		expect: attrs{
+			"subnet-id": "subnet-a1b2c3d4",
+		},
+		vpcID: "vpc-abcd",
+	}, {
+		config: attrs{
+			"vpc-id":    "vpc-abcd",
+			"subnet-id": "bad",
+		},
+		err: `.*subnet-id: "bad" is not a valid AWS subnet ID`,
+	}, {
+		config: attrs{
+			"subnet-id": "subnet-a1b2c3d4",
+		},
+		err: `.*cannot use subnet-id without specifying vpc-id as well`,
+	}, {
+		config: attrs{
+			"vpc-id":    "vpc-abcd",
+			"subnet-id": "subnet-a1b2c3d4",
+		},
+		change: attrs{
+			"vpc-id":    "vpc-abcd",
+			"subnet-id": "subnet-e5f6",
+		},
+		err:   `.*cannot change subnet-id from "subnet-a1b2c3d4" to "subnet-e5f6"`,
+		vpcID: "vpc-abcd",
+	}, {
+		expect: attrs{
+			"virt-type": "auto",
+		},
+	}, {
+		config: attrs{
+			"virt-type": "pv",
+		},
+		expect: attrs{
+			"virt-type": "pv",
+		},
+	}, {
+		config: attrs{
+			"virt-type": "xen",
+		},
+		err: `.*virt-type: "xen" is not valid \(expected "hvm", "pv" or "auto"\)`,
+	}, {
+		config: attrs{
+			"ec2-endpoint": "https://ec2-fips.us-east-1.amazonaws.com",
+			"s3-endpoint":  "https://s3-fips.us-east-1.amazonaws.com",
+		},
+		expect: attrs{
+			"ec2-endpoint": "https://ec2-fips.us-east-1.amazonaws.com",
+			"s3-endpoint":  "https://s3-fips.us-east-1.amazonaws.com",
+		},
+	}, {
+		config: attrs{
+			"ec2-endpoint": "https://ec2-fips.us-east-1.amazonaws.com",
+		},
+		expect: attrs{
+			"ec2-endpoint": "https://ec2-fips.us-east-1.amazonaws.com",
+			"s3-endpoint":  "",
+		},
+	}, {
+		config: attrs{
+			"ec2-endpoint": "ec2-fips.us-east-1.amazonaws.com",
+		},
+		err: `.*ec2-endpoint: "ec2-fips.us-east-1.amazonaws.com" is not a valid URL`,
+	}, {
+		config: attrs{
+			"s3-endpoint": "ftp://s3.example.com",
+		},
+		err: `.*s3-endpoint: "ftp://s3.example.com" is not a valid URL`,
+	}, {
+		config: attrs{
+			"ec2-endpoint": "https://ec2-fips.us-east-1.amazonaws.com",
+		},
+		change: attrs{
+			"ec2-endpoint": "https://ec2.us-east-1.amazonaws.com",
+		},
+		err: `.*cannot change ec2-endpoint from "https://ec2-fips.us-east-1.amazonaws.com" to "https://ec2.us-east-1.amazonaws.com"`,
 	}, {
 		config: attrs{
 			"future": "hammerstein",
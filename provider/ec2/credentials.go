@@ -28,12 +28,20 @@ func (environProviderCredentials) CredentialSchemas() map[cloud.AuthType]cloud.C
 				"access-key",
 				cloud.CredentialAttr{
 					Description: "The EC2 access key",
+					Optional:    true,
 				},
 			}, {
 				"secret-key",
 				cloud.CredentialAttr{
 					Description: "The EC2 secret key",
 					Hidden:      true,
+					Optional:    true,
+				},
+			}, {
+				"aws-profile",
+				cloud.CredentialAttr{
+					Description: "The AWS shared credentials profile to use if no keys are specified",
+					Optional:    true,
 				},
 			},
 		},
@@ -56,10 +64,6 @@ func (e environProviderCredentials) DetectCredentials() (*cloud.CloudCredential,
 		return e.detectEnvCredentials()
 	}
 
-	type accessKeyValues struct {
-		AwsAccessKeyId     string
-		AwsSecretAccessKey string
-	}
 	result := cloud.CloudCredential{
 		AuthCredentials: make(map[string]cloud.Credential),
 	}
@@ -98,6 +102,71 @@ func (e environProviderCredentials) DetectCredentials() (*cloud.CloudCredential,
 	return &result, nil
 }
 
+// accessKeyValues holds the keys in a section of the AWS shared
+// credentials file.
+type accessKeyValues struct {
+	AwsAccessKeyId     string
+	AwsSecretAccessKey string
+}
+
+// resolveAuth returns the AWS keys to use for the given credential
+// attributes. The keys are taken from the first of these to provide
+// them:
+//   - the "access-key" and "secret-key" attributes;
+//   - the AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY (or
+//     AWS_ACCESS_KEY and AWS_SECRET_KEY) environment variables;
+//   - the section of the AWS shared credentials file named by the
+//     "aws-profile" attribute.
+func resolveAuth(credentialAttrs map[string]string) (aws.Auth, error) {
+	accessKey := credentialAttrs["access-key"]
+	secretKey := credentialAttrs["secret-key"]
+	if accessKey != "" || secretKey != "" {
+		if accessKey == "" || secretKey == "" {
+			return aws.Auth{}, errors.NotValidf("credential with only one of access-key and secret-key")
+		}
+		return aws.Auth{AccessKey: accessKey, SecretKey: secretKey}, nil
+	}
+	if auth, err := aws.EnvAuth(); err == nil {
+		return auth, nil
+	}
+	profile := credentialAttrs["aws-profile"]
+	if profile == "" {
+		return aws.Auth{}, errors.NotValidf("credential with no access-key, secret-key or aws-profile")
+	}
+	auth, err := profileAuth(profile)
+	if err != nil {
+		return aws.Auth{}, errors.Annotatef(err, "reading AWS profile %q", profile)
+	}
+	return auth, nil
+}
+
+// profileAuth returns the keys for the named profile in the AWS shared
+// credentials file.
+func profileAuth(profile string) (aws.Auth, error) {
+	credsFile := filepath.Join(credentialsDir(), "credentials")
+	credInfo, err := ini.LooseLoad(credsFile)
+	if err != nil {
+		return aws.Auth{}, errors.Annotate(err, "loading AWS credentials file")
+	}
+	credInfo.NameMapper = ini.TitleUnderscore
+
+	section, err := credInfo.GetSection(profile)
+	if err != nil {
+		return aws.Auth{}, errors.NotFoundf("profile in %s", credsFile)
+	}
+	values := new(accessKeyValues)
+	if err := section.MapTo(values); err != nil {
+		return aws.Auth{}, errors.Annotate(err, "invalid credential attributes")
+	}
+	if values.AwsAccessKeyId == "" || values.AwsSecretAccessKey == "" {
+		return aws.Auth{}, errors.NotValidf("profile with missing aws credential attributes")
+	}
+	return aws.Auth{
+		AccessKey: values.AwsAccessKeyId,
+		SecretKey: values.AwsSecretAccessKey,
+	}, nil
+}
+
 func credentialsDir() string {
 	if runtime.GOOS == "windows" {
 		return filepath.Join(os.Getenv("USERPROFILE"), ".aws")
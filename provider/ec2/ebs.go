@@ -340,6 +340,9 @@ func (v *ebsVolumeSource) createVolume(p storage.VolumeParams, instances instanc
 	}
 	vol, _ := parseVolumeOptions(p.Size, p.Attributes)
 	vol.AvailZone = inst.AvailZone
+	if v.env.ecfg().encryptedStorage() {
+		vol.Encrypted = true
+	}
 	resp, err := v.env.ec2.CreateVolume(vol)
 	if err != nil {
 		return nil, nil, errors.Trace(err)
@@ -301,6 +301,24 @@ func (s *ebsSuite) TestVolumeTags(c *gc.C) {
 	})
 }
 
+func (s *ebsSuite) TestCreateVolumesEncryptedStorage(c *gc.C) {
+	s.PatchValue(&s.TestConfig, s.TestConfig.Merge(testing.Attrs{
+		"encrypted-storage": true,
+	}))
+	vs := s.volumeSource(c, nil)
+	results, err := s.createVolumes(vs, "")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(results, gc.HasLen, 3)
+
+	ec2Client := ec2.StorageEC2(vs)
+	ec2Vols, err := ec2Client.Volumes(nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(ec2Vols.Volumes, gc.HasLen, 3)
+	for _, vol := range ec2Vols.Volumes {
+		c.Check(vol.Encrypted, jc.IsTrue)
+	}
+}
+
 func (s *ebsSuite) TestVolumeTypeAliases(c *gc.C) {
 	instanceIdRunning := s.srv.ec2srv.NewInstances(1, "m1.medium", imageId, ec2test.Running, nil)[0]
 	vs := s.volumeSource(c, nil)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/rand"
 	"net"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/juju/retry"
 	"github.com/juju/utils"
 	"github.com/juju/utils/clock"
+	"github.com/juju/utils/set"
 	"gopkg.in/amz.v3/ec2"
 	"gopkg.in/amz.v3/s3"
 	"gopkg.in/juju/names.v2"
@@ -96,6 +98,9 @@ func (e *environ) Name() string {
 
 // PrepareForBootstrap is part of the Environ interface.
 func (env *environ) PrepareForBootstrap(ctx environs.BootstrapContext) error {
+	if err := checkRegionSupported(env.cloud.Region); err != nil {
+		return errors.Trace(err)
+	}
 	if ctx.ShouldVerifyCredentials() {
 		if err := verifyCredentials(env); err != nil {
 			return err
@@ -106,6 +111,11 @@ func (env *environ) PrepareForBootstrap(ctx environs.BootstrapContext) error {
 	if err := validateBootstrapVPC(env.ec2, env.cloud.Region, vpcID, forceVPCID, ctx); err != nil {
 		return errors.Trace(err)
 	}
+	if subnetID := ecfg.subnetID(); subnetID != "" {
+		if _, err := getVPCSubnet(env.ec2, vpcID, subnetID); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	return nil
 }
 
@@ -118,6 +128,11 @@ func (env *environ) Create(args environs.CreateParams) error {
 	if err := validateModelVPC(env.ec2, env.name, vpcID); err != nil {
 		return errors.Trace(err)
 	}
+	if subnetID := env.ecfg().subnetID(); subnetID != "" {
+		if _, err := getVPCSubnet(env.ec2, vpcID, subnetID); err != nil {
+			return errors.Trace(err)
+		}
+	}
 	// TODO(axw) 2016-08-04 #1609643
 	// Create global security group(s) here.
 	return nil
@@ -127,11 +142,87 @@ func (env *environ) validateVPC(logInfof func(string, ...interface{}), badge str
 	return nil
 }
 
-// Bootstrap is part of the Environ interface.
+// Bootstrap is part of the Environ interface. If the placement is
+// "instance-id=<id>", the existing instance is adopted as the
+// controller instead of a new one being started.
 func (e *environ) Bootstrap(ctx environs.BootstrapContext, args environs.BootstrapParams) (*environs.BootstrapResult, error) {
+	if err := e.checkNotBootstrapped(args.ControllerConfig.ControllerUUID(), args.Force); err != nil {
+		return nil, errors.Trace(err)
+	}
+	// Check the extra security groups up front, rather than
+	// after the tools and image have been chosen.
+	if _, err := e.extraSecurityGroups(); err != nil {
+		return nil, errors.Trace(err)
+	}
+	if id, ok, err := adoptedInstanceId(args.Placement); err != nil {
+		return nil, errors.Trace(err)
+	} else if ok {
+		return e.adoptBootstrapInstance(ctx, args, id)
+	}
 	return common.Bootstrap(ctx, e, args)
 }
 
+// checkNotBootstrapped returns an error satisfying
+// environs.IsAlreadyBootstrapped if there are instances of the given
+// controller that have not been terminated. If force is true, only
+// instances that are still pending or running are considered, so that
+// the remains of a crashed bootstrap do not prevent another.
+func (e *environ) checkNotBootstrapped(controllerUUID string, force bool) error {
+	states := []string{"pending", "running", "stopping", "stopped"}
+	if force {
+		states = aliveInstanceStates
+	}
+	filter := ec2.NewFilter()
+	filter.Add("instance-state-name", states...)
+	filter.Add(fmt.Sprintf("tag:%s", tags.JujuIsController), "true")
+	e.addControllerFilter(filter, controllerUUID)
+	ids, err := e.allInstanceIDs(filter)
+	if err != nil {
+		return errors.Annotate(err, "checking for existing controller instances")
+	}
+	if len(ids) > 0 {
+		return errors.Annotatef(environs.ErrAlreadyBootstrapped, "found controller instances %v", ids)
+	}
+	return nil
+}
+
+// CheckBootstrap is specified on the environs.BootstrapChecker interface.
+func (e *environ) CheckBootstrap(ctx environs.BootstrapContext, args environs.BootstrapParams) error {
+	if err := verifyCredentials(e); err != nil {
+		return err
+	}
+	if _, err := e.extraSecurityGroups(); err != nil {
+		return errors.Trace(err)
+	}
+	if err := e.checkNotBootstrapped(args.ControllerConfig.ControllerUUID(), args.Force); err != nil {
+		return errors.Trace(err)
+	}
+	series := args.BootstrapSeries
+	if series == "" {
+		series = config.PreferredSeries(e.Config())
+	}
+	availableTools, err := args.AvailableTools.Match(tools.Filter{Series: series})
+	if err != nil {
+		return err
+	}
+	arches := availableTools.Arches()
+	spec, err := findInstanceSpec(args.ImageMetadata, &instances.InstanceConstraint{
+		Region:      e.cloud.Region,
+		Series:      series,
+		Arches:      arches,
+		Constraints: e.withImageVirtType(args.BootstrapConstraints),
+		Storage:     e.imageStorageTypes(),
+	}, false)
+	if err != nil {
+		return err
+	}
+	if _, err := availableTools.Match(tools.Filter{Arch: spec.Image.Arch}); err != nil {
+		return errors.Errorf("chosen architecture %v not present in %v", spec.Image.Arch, arches)
+	}
+	ctx.Verbosef("Controller would be started on %s instance with image %s", spec.InstanceType.Name, spec.Image.Id)
+	return nil
+}
+
 // SupportsSpaces is specified on environs.Networking.
 func (e *environ) SupportsSpaces() (bool, error) {
 	return true, nil
@@ -244,14 +335,25 @@ func (e *environ) parsePlacement(placement string) (*ec2Placement, error) {
 		if err != nil {
 			return nil, err
 		}
-		for _, z := range zones {
+		zoneNames := make([]string, len(zones))
+		for i, z := range zones {
 			if z.Name() == availabilityZone {
 				return &ec2Placement{
 					z.(*ec2AvailabilityZone).AvailabilityZoneInfo,
 				}, nil
 			}
+			zoneNames[i] = z.Name()
+		}
+		sort.Strings(zoneNames)
+		return nil, fmt.Errorf(
+			"invalid availability zone %q; valid zones for region %q are: %s",
+			availabilityZone, e.cloud.Region, strings.Join(zoneNames, ", "),
+		)
+	case "instance-id":
+		if _, _, err := adoptedInstanceId(placement); err != nil {
+			return nil, errors.Trace(err)
 		}
-		return nil, fmt.Errorf("invalid availability zone %q", availabilityZone)
+		return nil, fmt.Errorf("cannot use placement %q: existing instances can only be adopted when bootstrapping", placement)
 	}
 	return nil, fmt.Errorf("unknown placement directive: %v", placement)
 }
@@ -272,7 +374,7 @@ func (e *environ) PrecheckInstance(series string, cons constraints.Value, placem
 			continue
 		}
 		if archMatches(itype.Arches, cons.Arch) {
-			return nil
+			return checkInstanceTypeAvailable(e.cloud.Region, itype.Name)
 		}
 	}
 	if cons.Arch == nil {
@@ -314,10 +416,39 @@ func (e *environ) cloudSpec(region string) (simplestreams.CloudSpec, error) {
 }
 
 const (
-	ebsStorage = "ebs"
-	ssdStorage = "ssd"
+	ebsStorage           = "ebs"
+	ssdStorage           = "ssd"
+	instanceStoreStorage = "instance"
+)
+
+const (
+	rootDeviceTypeEBS           = "ebs"
+	rootDeviceTypeInstanceStore = "instance-store"
 )
 
+// virtTypeAuto is the virt-type value with which the virtualization
+// type of images is chosen to suit the instance type.
+const virtTypeAuto = "auto"
+
+// imageStorageTypes returns the image root store types, as recorded in
+// image metadata, that are suitable for the configured root-device-type,
+// in order of preference.
+func (e *environ) imageStorageTypes() []string {
+	if e.ecfg().rootDeviceType() == rootDeviceTypeInstanceStore {
+		return []string{instanceStoreStorage}
+	}
+	return []string{ssdStorage, ebsStorage}
+}
+
+// withImageVirtType returns cons with its virtualization type set to
+// the configured virt-type, unless that is "auto".
+func (e *environ) withImageVirtType(cons constraints.Value) constraints.Value {
+	if virtType := e.ecfg().virtType(); virtType != virtTypeAuto {
+		cons.VirtType = &virtType
+	}
+	return cons
+}
+
 // DistributeInstances implements the state.InstanceDistributor policy.
 func (e *environ) DistributeInstances(candidates, distributionGroup []instance.Id) ([]instance.Id, error) {
 	return common.DistributeInstances(e, candidates, distributionGroup)
@@ -363,6 +494,28 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		availabilityZones = append(availabilityZones, placement.availabilityZone.Name)
 	}
 
+	// If a subnet-id is configured, every instance is started in that
+	// subnet, and therefore in the availability zone containing it.
+	subnetID := e.ecfg().subnetID()
+	if subnetID != "" {
+		subnet, err := getVPCSubnet(e.ec2, e.ecfg().vpcID(), subnetID)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if len(availabilityZones) > 0 && availabilityZones[0] != subnet.AvailZone {
+			return nil, errors.Errorf(
+				"subnet %q is in availability zone %q, not %q",
+				subnetID, subnet.AvailZone, availabilityZones[0],
+			)
+		}
+		if len(args.SubnetsToZones) > 0 {
+			if _, ok := args.SubnetsToZones[network.Id(subnetID)]; !ok {
+				return nil, errors.Errorf("subnet %q does not match spaces constraints", subnetID)
+			}
+		}
+		availabilityZones = []string{subnet.AvailZone}
+	}
+
 	// If no availability zone is specified, then automatically spread across
 	// the known zones for optimal spread across the instance distribution
 	// group.
@@ -388,15 +541,55 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		}
 	}
 
+	if args.Constraints.HasInstanceType() {
+		// Check the requested instance type up front, so the user is
+		// told which instance types they may choose from.
+		err := checkInstanceTypeAvailable(e.cloud.Region, *args.Constraints.InstanceType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+	}
+
+	cons := args.Constraints
+	if rootDisk := e.ecfg().rootDisk(); rootDisk > 0 && cons.RootDisk == nil {
+		// Unlike the root-disk constraint, which is ignored when too
+		// small, the configured size is an error if the image won't fit.
+		rootDiskMiB := uint64(rootDisk)
+		if minSize := minRootDiskSizeMiB(args.InstanceConfig.Series); rootDiskMiB < minSize {
+			return nil, errors.Errorf(
+				"root-disk of %dM is smaller than the EC2 image size of %dM",
+				rootDiskMiB, minSize,
+			)
+		}
+		cons.RootDisk = &rootDiskMiB
+	}
+
 	arches := args.Tools.Arches()
+	if args.Constraints.HasInstanceType() && !args.Constraints.HasArch() {
+		// The instance type determines the architecture, so choose
+		// from the tools for that architecture if there are any.
+		instanceArch, err := ArchForInstanceType(*args.Constraints.InstanceType)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		if set.NewStrings(arches...).Contains(instanceArch) {
+			arches = []string{instanceArch}
+		}
+	}
 
+	// Controllers are never provisioned as spot instances, as EC2 may
+	// reclaim spot instances at any time.
+	var spotPrice string
+	if args.InstanceConfig.Controller == nil {
+		spotPrice = e.ecfg().spotPrice()
+	}
 	spec, err := findInstanceSpec(args.ImageMetadata, &instances.InstanceConstraint{
 		Region:      e.cloud.Region,
 		Series:      args.InstanceConfig.Series,
 		Arches:      arches,
-		Constraints: args.Constraints,
-		Storage:     []string{ssdStorage, ebsStorage},
-	})
+		Constraints: e.withImageVirtType(args.Constraints),
+		Storage:     e.imageStorageTypes(),
+	}, spotPrice != "")
 	if err != nil {
 		return nil, err
 	}
@@ -416,11 +609,16 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		return nil, err
 	}
 
-	userData, err := providerinit.ComposeUserData(args.InstanceConfig, nil, AmazonRenderer{})
+	userData, err := providerinit.ComposeUserData(args.InstanceConfig, nil, AmazonRenderer{
+		UserData: e.ecfg().cloudInitUserData(),
+	})
 	if err != nil {
 		return nil, errors.Annotate(err, "cannot make user data")
 	}
 	logger.Debugf("ec2 user data; %d bytes", len(userData))
+	if err := checkUserDataSize(userData); err != nil {
+		return nil, errors.Trace(err)
+	}
 	var apiPort int
 	if args.InstanceConfig.Controller != nil {
 		apiPort = args.InstanceConfig.Controller.Config.APIPort()
@@ -431,9 +629,25 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 	if err != nil {
 		return nil, errors.Annotate(err, "cannot set up groups")
 	}
+	extraGroups, err := e.extraSecurityGroups()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	groups = append(groups, extraGroups...)
 
-	blockDeviceMappings := getBlockDeviceMappings(args.Constraints, args.InstanceConfig.Series)
+	blockDeviceMappings := getBlockDeviceMappings(cons, args.InstanceConfig.Series)
 	rootDiskSize := uint64(blockDeviceMappings[0].VolumeSize) * 1024
+	rootDisk := &rootDiskSize
+	if e.ecfg().rootDeviceType() == rootDeviceTypeInstanceStore {
+		// The image's root device is an instance store, whose size
+		// is determined by the instance type, so there is no root
+		// EBS volume to map.
+		if cons.RootDisk != nil {
+			logger.Infof("ignoring root-disk of %dM for instance-store root device", *cons.RootDisk)
+		}
+		blockDeviceMappings = blockDeviceMappings[1:]
+		rootDisk = nil
+	}
 
 	// If --constraints spaces=foo was passed, the provisioner will populate
 	// args.SubnetsToZones map. In AWS a subnet can span only one zone, so here
@@ -448,6 +662,13 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		logger.Infof("ignoring all but the first positive space from constraints: %v", spaces)
 	}
 
+	instanceProfile := e.ecfg().iamInstanceProfile()
+	if args.InstanceConfig.Controller != nil {
+		if profile := e.ecfg().controllerIAMInstanceProfile(); profile != "" {
+			instanceProfile = profile
+		}
+	}
+
 	var instResp *ec2.RunInstancesResp
 	commonRunArgs := &ec2.RunInstances{
 		MinCount:            1,
@@ -457,6 +678,8 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		SecurityGroups:      groups,
 		BlockDeviceMappings: blockDeviceMappings,
 		ImageId:             spec.Image.Id,
+		IAMInstanceProfile:  instanceProfile,
+		PlacementGroupName:  e.ecfg().placementGroup(),
 	}
 
 	haveVPCID := isVPCIDSet(e.ecfg().vpcID())
@@ -467,7 +690,9 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 
 		var subnetIDsForZone []string
 		var subnetErr error
-		if haveVPCID {
+		if subnetID != "" {
+			subnetIDsForZone = []string{subnetID}
+		} else if haveVPCID {
 			var allowedSubnetIDs []string
 			for subnetID, _ := range args.SubnetsToZones {
 				allowedSubnetIDs = append(allowedSubnetIDs, string(subnetID))
@@ -499,7 +724,11 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 			logger.Infof("selected subnet %q in zone %q", runArgs.SubnetId, zone)
 		}
 
-		instResp, err = runInstances(e.ec2, runArgs)
+		if spotPrice != "" {
+			instResp, err = requestSpotInstances(e.ec2, runArgs, spotPrice)
+		} else {
+			instResp, err = runInstances(e.ec2, runArgs)
+		}
 		if err == nil || !isZoneOrSubnetConstrainedError(err) {
 			break
 		}
@@ -508,6 +737,9 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 	}
 
 	if err != nil {
+		if group := commonRunArgs.PlacementGroupName; group != "" {
+			return nil, errors.Annotatef(err, "cannot run instances in placement group %q", group)
+		}
 		return nil, errors.Annotate(err, "cannot run instances")
 	}
 	if len(instResp.Instances) != 1 {
@@ -518,6 +750,16 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		e:        e,
 		Instance: &instResp.Instances[0],
 	}
+	if inst.Instance.VirtType == "" {
+		// The launch response may not report the virtualization
+		// type; the image we chose has it.
+		inst.Instance.VirtType = spec.Image.VirtType
+	}
+	imageInfo := inst.ImageInfo()
+	logger.Infof(
+		"started instance %q from image %q (%s, %s root device)",
+		inst.Id(), imageInfo.ImageId, imageInfo.VirtType, imageInfo.RootDeviceType,
+	)
 	instAZ := inst.Instance.AvailZone
 	if haveVPCID {
 		instVPC := e.ecfg().vpcID()
@@ -555,7 +797,7 @@ func (e *environ) StartInstance(args environs.StartInstanceParams) (_ *environs.
 		Mem:      &spec.InstanceType.Mem,
 		CpuCores: &spec.InstanceType.CpuCores,
 		CpuPower: spec.InstanceType.CpuPower,
-		RootDisk: &rootDiskSize,
+		RootDisk: rootDisk,
 		// Tags currently not supported by EC2
 		AvailabilityZone: &inst.Instance.AvailZone,
 	}
@@ -731,6 +973,9 @@ func (e *environ) gatherInstances(
 	if err != nil {
 		return err
 	}
+	if err := validateInstancesResp(resp); err != nil {
+		return errors.Trace(err)
+	}
 	n := 0
 	// For each requested id, add it to the returned instances
 	// if we find it in the response.
@@ -758,6 +1003,18 @@ func (e *environ) gatherInstances(
 	return nil
 }
 
+// InstancesByTag returns all alive instances that have all of the
+// specified tags. This queries EC2 directly, and so does not depend on
+// any state recorded by Juju.
+func (e *environ) InstancesByTag(tagValues map[string]string) ([]instance.Instance, error) {
+	filter := ec2.NewFilter()
+	filter.Add("instance-state-name", aliveInstanceStates...)
+	for k, v := range tagValues {
+		filter.Add(fmt.Sprintf("tag:%s", k), v)
+	}
+	return e.allInstances(filter)
+}
+
 // NetworkInterfaces implements NetworkingEnviron.NetworkInterfaces.
 func (e *environ) NetworkInterfaces(instId instance.Id) ([]network.InterfaceInfo, error) {
 	var err error
@@ -961,14 +1218,17 @@ func (e *environ) AllInstancesByState(states ...string) ([]instance.Instance, er
 
 // ControllerInstances is part of the environs.Environ interface.
 func (e *environ) ControllerInstances(controllerUUID string) ([]instance.Id, error) {
-	filter := ec2.NewFilter()
-	filter.Add("instance-state-name", aliveInstanceStates...)
-	filter.Add(fmt.Sprintf("tag:%s", tags.JujuIsController), "true")
-	e.addControllerFilter(filter, controllerUUID)
-	ids, err := e.allInstanceIDs(filter)
+	insts, err := e.InstancesByTag(map[string]string{
+		tags.JujuIsController: "true",
+		tags.JujuController:   controllerUUID,
+	})
 	if err != nil {
 		return nil, errors.Trace(err)
 	}
+	ids := make([]instance.Id, len(insts))
+	for i, inst := range insts {
+		ids[i] = inst.Id()
+	}
 	if len(ids) == 0 {
 		return nil, environs.ErrNotBootstrapped
 	}
@@ -1004,6 +1264,9 @@ func (e *environ) allInstances(filter *ec2.Filter) ([]instance.Instance, error)
 	if err != nil {
 		return nil, errors.Annotate(err, "listing instances")
 	}
+	if err := validateInstancesResp(resp); err != nil {
+		return nil, errors.Trace(err)
+	}
 	var insts []instance.Instance
 	for _, r := range resp.Reservations {
 		for i := range r.Instances {
@@ -1017,12 +1280,29 @@ func (e *environ) allInstances(filter *ec2.Filter) ([]instance.Instance, error)
 
 // Destroy is part of the environs.Environ interface.
 func (e *environ) Destroy() error {
+	var instIds []instance.Id
+	timeout := e.ecfg().terminateTimeout()
+	if timeout > 0 {
+		insts, err := e.AllInstances()
+		if err != nil {
+			return errors.Annotate(err, "listing instances")
+		}
+		for _, inst := range insts {
+			instIds = append(instIds, inst.Id())
+		}
+	}
 	if err := common.Destroy(e); err != nil {
 		return errors.Trace(err)
 	}
+	if err := e.waitInstancesTerminated(instIds, timeout); err != nil {
+		return errors.Trace(err)
+	}
 	if err := e.cleanEnvironmentSecurityGroups(); err != nil {
 		return errors.Annotate(err, "cannot delete environment security groups")
 	}
+	if err := e.destroyLeakedResources(); err != nil {
+		return errors.Annotate(err, "cleaning up model resources")
+	}
 	return nil
 }
 
@@ -1311,6 +1591,90 @@ func (e *environ) terminateInstances(ids []instance.Id) error {
 	return nil
 }
 
+// terminatePollDelay is the time between checks made by
+// waitInstancesTerminated.
+var terminatePollDelay = 5 * time.Second
+
+// waitInstancesTerminated waits up to timeout for the instances with
+// the given IDs to reach the terminated state. Instances that cannot be
+// found are taken to have terminated. If any of them are still in
+// another state when the timeout elapses, an error naming them is
+// returned.
+func (e *environ) waitInstancesTerminated(ids []instance.Id, timeout time.Duration) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	remaining := make([]string, len(ids))
+	for i, id := range ids {
+		remaining[i] = string(id)
+	}
+	// TODO(katco): 2016-08-09: lp:1611427
+	strategy := utils.AttemptStrategy{Total: timeout, Delay: terminatePollDelay}
+	for a := strategy.Start(); a.Next(); {
+		filter := ec2.NewFilter()
+		filter.Add("instance-state-name", "pending", "running", "shutting-down", "stopping", "stopped")
+		filter.Add("instance-id", remaining...)
+		resp, err := e.ec2.Instances(nil, filter)
+		if err != nil {
+			return errors.Annotate(err, "checking for terminated instances")
+		}
+		var notTerminated []string
+		for _, r := range resp.Reservations {
+			for _, inst := range r.Instances {
+				notTerminated = append(notTerminated, inst.InstanceId)
+			}
+		}
+		if len(notTerminated) == 0 {
+			return nil
+		}
+		remaining = notTerminated
+		logger.Debugf("waiting for instances to terminate: %v", remaining)
+	}
+	sort.Strings(remaining)
+	return errors.Errorf(
+		"instances not terminated after %s: %s",
+		timeout, strings.Join(remaining, ", "),
+	)
+}
+
+// WaitForInstanceState polls the instance with the given id until it
+// reaches the target state, or the strategy expires. If the target is
+// "running" and the instance is found to be shutting down or
+// terminated, an error is returned immediately, as it will never
+// reach the target.
+func WaitForInstanceState(env environs.Environ, id instance.Id, target ec2.InstanceState, strategy utils.AttemptStrategy) error {
+	e, ok := env.(*environ)
+	if !ok {
+		return errors.NotValidf("non-ec2 environ %T", env)
+	}
+	var current string
+	for a := strategy.Start(); a.Next(); {
+		resp, err := e.ec2.Instances([]string{string(id)}, nil)
+		if err != nil {
+			if ec2ErrCode(err) == "InvalidInstanceID.NotFound" {
+				// The instance may not be visible yet due to
+				// eventual consistency.
+				continue
+			}
+			return errors.Annotatef(err, "fetching instance %q", id)
+		}
+		for _, r := range resp.Reservations {
+			for _, inst := range r.Instances {
+				current = inst.State.Name
+			}
+		}
+		switch current {
+		case target.Name:
+			return nil
+		case "shutting-down", "terminated":
+			if target.Name == "running" {
+				return errors.Errorf("instance %q is %s", id, current)
+			}
+		}
+	}
+	return errors.Errorf("timed out waiting for instance %q to be %s (state %q)", id, target.Name, current)
+}
+
 var terminateInstancesById = func(ec2inst *ec2.EC2, ids ...instance.Id) (*ec2.TerminateInstancesResp, error) {
 	strs := make([]string, len(ids))
 	for i, id := range ids {
@@ -1339,10 +1703,16 @@ func (e *environ) deleteSecurityGroupsForInstances(ids []instance.Id) {
 	// https://bugs.launchpad.net/juju-core/+bug/1534289
 	jujuGroup := e.jujuGroupName()
 
+	// Groups from extra-security-groups are not juju's to delete.
+	extraGroups := set.NewStrings(e.ecfg().extraSecurityGroups()...)
+
 	for _, deletable := range securityGroups {
 		if deletable.Name == jujuGroup {
 			continue
 		}
+		if extraGroups.Contains(deletable.Id) || extraGroups.Contains(deletable.Name) {
+			continue
+		}
 		if err := deleteSecurityGroupInsistently(e.ec2, deletable, clock.WallClock); err != nil {
 			// In ideal world, we would err out here.
 			// However:
@@ -1465,6 +1835,45 @@ func (e *environ) setUpGroups(controllerUUID, machineId string, apiPort int) ([]
 	return []ec2.SecurityGroup{jujuGroup, machineGroup}, nil
 }
 
+// extraSecurityGroups returns the existing security groups named by
+// the extra-security-groups config attribute, each of which may be
+// given by ID or by name. If any of them cannot be found, in the
+// model's VPC when one is specified, an error naming them is returned.
+func (e *environ) extraSecurityGroups() ([]ec2.SecurityGroup, error) {
+	wanted := e.ecfg().extraSecurityGroups()
+	if len(wanted) == 0 {
+		return nil, nil
+	}
+	var filter *ec2.Filter
+	if chosenVPCID := e.ecfg().vpcID(); isVPCIDSet(chosenVPCID) {
+		filter = ec2.NewFilter()
+		filter.Add("vpc-id", chosenVPCID)
+	}
+	resp, err := e.ec2.SecurityGroups(nil, filter)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing security groups")
+	}
+	var groups []ec2.SecurityGroup
+	var missing []string
+	for _, name := range wanted {
+		found := false
+		for _, info := range resp.Groups {
+			if info.Id == name || info.Name == name {
+				groups = append(groups, ec2.SecurityGroup{Id: info.Id, Name: info.Name})
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.NotFoundf("extra security groups %s", strings.Join(missing, ", "))
+	}
+	return groups, nil
+}
+
 // zeroGroup holds the zero security group.
 var zeroGroup ec2.SecurityGroup
 
@@ -286,6 +286,24 @@ func getVPCSubnets(apiClient vpcAPIClient, vpc *ec2.VPC) ([]ec2.Subnet, error) {
 	return response.Subnets, nil
 }
 
+// getVPCSubnet returns the subnet with the given subnetID, if it is part
+// of the VPC with the given vpcID. Returns an error satisfying
+// errors.IsNotFound() when the VPC has no such subnet.
+func getVPCSubnet(apiClient vpcAPIClient, vpcID, subnetID string) (*ec2.Subnet, error) {
+	vpc := &ec2.VPC{Id: vpcID}
+	subnets, err := getVPCSubnets(apiClient, vpc)
+	if err != nil && !isVPCNotUsableError(err) {
+		return nil, errors.Annotatef(err, "cannot get VPC %q subnets", vpcID)
+	}
+
+	for _, subnet := range subnets {
+		if subnet.Id == subnetID {
+			return &subnet, nil
+		}
+	}
+	return nil, errors.NotFoundf("subnet %q in VPC %q", subnetID, vpcID)
+}
+
 func findFirstPublicSubnet(subnets []ec2.Subnet) (*ec2.Subnet, error) {
 	for _, subnet := range subnets {
 		// TODO(dimitern): goamz's AddDefaultVPCAndSubnets() does not set
@@ -306,6 +306,40 @@ func (s *vpcSuite) TestGetVPCSubnetsSuccess(c *gc.C) {
 	s.stubAPI.CheckSingleSubnetsCall(c, anyVPC)
 }
 
+func (s *vpcSuite) TestGetVPCSubnetSuccess(c *gc.C) {
+	s.stubAPI.SetSubnetsResponse(3, anyZone, noPublicIPOnLaunch)
+
+	anyVPC := makeEC2VPC(anyVPCID, anyState)
+	subnet, err := getVPCSubnet(s.stubAPI, anyVPC.Id, "subnet-1")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Check(*subnet, jc.DeepEquals, s.stubAPI.subnetsResponse.Subnets[1])
+
+	s.stubAPI.CheckSingleSubnetsCall(c, anyVPC)
+}
+
+func (s *vpcSuite) TestGetVPCSubnetNotFound(c *gc.C) {
+	s.stubAPI.SetSubnetsResponse(3, anyZone, noPublicIPOnLaunch)
+
+	anyVPC := makeEC2VPC(anyVPCID, anyState)
+	subnet, err := getVPCSubnet(s.stubAPI, anyVPC.Id, "subnet-42")
+	c.Assert(err, gc.ErrorMatches, `subnet "subnet-42" in VPC "vpc-anything" not found`)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+	c.Check(subnet, gc.IsNil)
+
+	s.stubAPI.CheckSingleSubnetsCall(c, anyVPC)
+}
+
+func (s *vpcSuite) TestGetVPCSubnetUnexpectedAWSError(c *gc.C) {
+	s.stubAPI.SetErrors(errors.New("AWS failed!"))
+
+	anyVPC := makeEC2VPC(anyVPCID, anyState)
+	subnet, err := getVPCSubnet(s.stubAPI, anyVPC.Id, "subnet-0")
+	c.Assert(err, gc.ErrorMatches, `cannot get VPC "vpc-anything" subnets: unexpected AWS .*: AWS failed!`)
+	c.Check(subnet, gc.IsNil)
+
+	s.stubAPI.CheckSingleSubnetsCall(c, anyVPC)
+}
+
 func (s *vpcSuite) TestFindFirstPublicSubnetSuccess(c *gc.C) {
 	s.stubAPI.SetSubnetsResponse(3, anyZone, withPublicIPOnLaunch)
 	s.stubAPI.subnetsResponse.Subnets[0].MapPublicIPOnLaunch = false
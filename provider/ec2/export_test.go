@@ -5,6 +5,7 @@ package ec2
 
 import (
 	"io"
+	"time"
 
 	"gopkg.in/amz.v3/aws"
 	"gopkg.in/amz.v3/ec2"
@@ -31,6 +32,14 @@ func MachineGroupName(e environs.Environ, machineId string) string {
 	return e.(*environ).machineGroupName(machineId)
 }
 
+func SpotPriceHistory(e environs.Environ, instanceType, az string, since time.Time) ([]SpotPrice, error) {
+	return e.(*environ).SpotPriceHistory(instanceType, az, since)
+}
+
+func ListManagedResources(e environs.Environ) ([]Resource, error) {
+	return e.(*environ).ListManagedResources()
+}
+
 func EnvironEC2(e environs.Environ) *ec2.EC2 {
 	return e.(*environ).ec2
 }
@@ -39,6 +48,10 @@ func InstanceEC2(inst instance.Instance) *ec2.Instance {
 	return inst.(*ec2Instance).Instance
 }
 
+func InstancesByTag(e environs.Environ, tags map[string]string) ([]instance.Instance, error) {
+	return e.(*environ).InstancesByTag(tags)
+}
+
 func TerminatedInstances(e environs.Environ) ([]instance.Instance, error) {
 	return e.(*environ).AllInstancesByState("shutting-down", "terminated")
 }
@@ -51,10 +64,20 @@ var (
 	EC2AvailabilityZones        = &ec2AvailabilityZones
 	AvailabilityZoneAllocations = &availabilityZoneAllocations
 	RunInstances                = &runInstances
+	RequestSpotInstances        = &requestSpotInstances
 	BlockDeviceNamer            = blockDeviceNamer
 	GetBlockDeviceMappings      = getBlockDeviceMappings
 	IsVPCNotUsableError         = isVPCNotUsableError
 	IsVPCNotRecommendedError    = isVPCNotRecommendedError
+	InstanceStatusPollInterval  = &instanceStatusPollInterval
+	ThrottleRetryDelay          = &throttleRetryDelay
+	RetryThrottled              = retryThrottled
+	CheckUserDataSize           = checkUserDataSize
+	DescribeSpotPriceHistory    = &describeSpotPriceHistory
+	InstanceIdValidatorVar      = &instanceIdValidator
+	CheckProvisioned            = &checkProvisioned
+	DetectSeriesAndHardware     = &detectSeriesAndHardwareCharacteristics
+	ConfigureMachine            = &configureMachine
 )
 
 const VPCIDNone = vpcIDNone
@@ -105,11 +128,20 @@ func UseTestInstanceTypeData(content instanceTypeCost) {
 var (
 	ShortAttempt                   = &shortAttempt
 	StorageAttempt                 = &storageAttempt
+	SpotRequestAttempt             = &spotRequestAttempt
 	DestroyVolumeAttempt           = &destroyVolumeAttempt
 	DeleteSecurityGroupInsistently = &deleteSecurityGroupInsistently
 	TerminateInstancesById         = &terminateInstancesById
+	TerminatePollDelay             = &terminatePollDelay
 )
 
+// MultipartUpload is the interface used by PutParts.
+type MultipartUpload multipartUpload
+
+func PutParts(upload MultipartUpload, r io.Reader, length, partSize int64, retries int) error {
+	return putParts(upload, r, length, partSize, retries)
+}
+
 func EC2ErrCode(err error) string {
 	return ec2ErrCode(err)
 }
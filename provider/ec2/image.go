@@ -5,7 +5,12 @@ package ec2
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
+	"github.com/juju/errors"
+
+	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs/imagemetadata"
 	"github.com/juju/juju/environs/instances"
 )
@@ -42,10 +47,46 @@ func filterImages(images []*imagemetadata.ImageMetadata, ic *instances.InstanceC
 	return imagesByStorage[""]
 }
 
+// filterImagesByVirtType returns the images (in the same order) with
+// the given virtualization type, or with none recorded.
+func filterImagesByVirtType(images []*imagemetadata.ImageMetadata, virtType string) []*imagemetadata.ImageMetadata {
+	var result []*imagemetadata.ImageMetadata
+	for _, image := range images {
+		if image.VirtType == "" || image.VirtType == virtType {
+			result = append(result, image)
+		}
+	}
+	return result
+}
+
+// checkInstanceTypeVirtType returns an error if cons names an instance
+// type that cannot run images of the virtualization type it requires,
+// so that the mismatch is reported before any instance is run.
+func checkInstanceTypeVirtType(cons constraints.Value) error {
+	if !cons.HasVirtType() || !cons.HasInstanceType() {
+		return nil
+	}
+	for _, itype := range allInstanceTypes {
+		if itype.Name != *cons.InstanceType || itype.VirtType == nil {
+			continue
+		}
+		if *itype.VirtType != *cons.VirtType {
+			return fmt.Errorf(
+				"instance type %q requires %s images, but virt-type is %q",
+				itype.Name, *itype.VirtType, *cons.VirtType,
+			)
+		}
+	}
+	return nil
+}
+
 // findInstanceSpec returns an InstanceSpec satisfying the supplied instanceConstraint.
+// If spot is true, only instance types that can be run as spot instances
+// are considered.
 func findInstanceSpec(
 	allImageMetadata []*imagemetadata.ImageMetadata,
 	ic *instances.InstanceConstraint,
+	spot bool,
 ) (*instances.InstanceSpec, error) {
 	logger.Debugf("received %d image(s)", len(allImageMetadata))
 	// If the instance type is set, don't also set a default CPU power
@@ -54,25 +95,90 @@ func findInstanceSpec(
 	if cons.CpuPower == nil && (cons.InstanceType == nil || *cons.InstanceType == "") {
 		ic.Constraints.CpuPower = instances.CpuPower(defaultCpuPower)
 	}
+	if err := checkInstanceTypeVirtType(ic.Constraints); err != nil {
+		return nil, err
+	}
+	if spot && cons.HasInstanceType() && !spotAvailable(*cons.InstanceType) {
+		return nil, fmt.Errorf("instance type %q cannot be run as a spot instance", *cons.InstanceType)
+	}
 	suitableImages := filterImages(allImageMetadata, ic)
 	logger.Debugf("found %d suitable image(s)", len(suitableImages))
+	if len(suitableImages) == 0 && len(allImageMetadata) > 0 && len(ic.Storage) > 0 {
+		return nil, fmt.Errorf(
+			"no %q images in %s with root store %s",
+			ic.Series, ic.Region, strings.Join(ic.Storage, " or "),
+		)
+	}
+	if ic.Constraints.HasVirtType() && len(suitableImages) > 0 {
+		suitableImages = filterImagesByVirtType(suitableImages, *ic.Constraints.VirtType)
+		if len(suitableImages) == 0 {
+			return nil, fmt.Errorf(
+				"no %q images in %s with virtualization type %s",
+				ic.Series, ic.Region, *ic.Constraints.VirtType,
+			)
+		}
+	}
 	images := instances.ImageMetadataToImages(suitableImages)
 
 	// Make a copy of the known EC2 instance types, filling in the cost for the specified region.
-	regionCosts := allRegionCosts[ic.Region]
-	if len(regionCosts) == 0 && len(allRegionCosts) > 0 {
+	if len(allRegionCosts[ic.Region]) == 0 && len(allRegionCosts) > 0 {
 		return nil, fmt.Errorf("no instance types found in %s", ic.Region)
 	}
+	itypes := regionInstanceTypes(ic.Region)
+	if spot {
+		itypes = filterSpotInstanceTypes(itypes)
+	}
+	return instances.FindInstanceSpec(images, ic, itypes)
+}
 
-	var itypesWithCosts []instances.InstanceType
-	for _, itype := range allInstanceTypes {
-		cost, ok := regionCosts[itype.Name]
-		if !ok {
-			continue
+// filterSpotInstanceTypes returns the instance types that can be run
+// as spot instances.
+func filterSpotInstanceTypes(itypes []instances.InstanceType) []instances.InstanceType {
+	var result []instances.InstanceType
+	for _, itype := range itypes {
+		if spotAvailable(itype.Name) {
+			result = append(result, itype)
+		}
+	}
+	return result
+}
+
+// checkRegionSupported returns an error satisfying errors.IsNotSupported
+// if there is no instance type data for the given region, as happens
+// when the region is newer than this version of juju.
+func checkRegionSupported(region string) error {
+	if _, ok := allRegionCosts[region]; !ok {
+		return errors.NewNotSupported(nil, fmt.Sprintf(
+			"region %q is not supported by this version of juju; please upgrade juju to use it",
+			region,
+		))
+	}
+	return nil
+}
+
+// checkInstanceTypeAvailable returns an error if the named instance type
+// is not available in the given region. The error lists the instance
+// types that are available, as returned by InstanceTypes, so the user
+// may choose a valid one.
+func checkInstanceTypeAvailable(region, instanceType string) error {
+	specs, err := InstanceTypes(region)
+	if errors.IsNotSupported(err) || (err == nil && len(specs) == 0) {
+		// We have no instance type data for the region,
+		// so we cannot say whether the type is available.
+		return nil
+	} else if err != nil {
+		return errors.Trace(err)
+	}
+	available := make([]string, len(specs))
+	for i, spec := range specs {
+		if spec.Name == instanceType {
+			return nil
 		}
-		itWithCost := itype
-		itWithCost.Cost = cost
-		itypesWithCosts = append(itypesWithCosts, itWithCost)
+		available[i] = spec.Name
 	}
-	return instances.FindInstanceSpec(images, ic, itypesWithCosts)
+	sort.Strings(available)
+	return fmt.Errorf(
+		"instance type %q not available in region %q (valid instance types: %s)",
+		instanceType, region, strings.Join(available, ", "),
+	)
 }
@@ -6,6 +6,7 @@ package ec2
 import (
 	"fmt"
 
+	"github.com/juju/errors"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils/series"
 	gc "gopkg.in/check.v1"
@@ -181,7 +182,7 @@ func (s *specSuite) TestFindInstanceSpec(c *gc.C) {
 				Arches:      test.arches,
 				Constraints: constraints.MustParse(test.cons),
 				Storage:     stor,
-			})
+			}, false)
 		c.Assert(err, jc.ErrorIsNil)
 		c.Check(spec.InstanceType.Name, gc.Equals, test.itype)
 		c.Check(spec.Image.Id, gc.Equals, test.image)
@@ -197,7 +198,7 @@ func (s *specSuite) TestFindInstanceSpecNotSetCpuPowerWhenInstanceTypeSet(c *gc.
 	}
 
 	c.Check(instanceConstraint.Constraints.CpuPower, gc.IsNil)
-	findInstanceSpec(TestImageMetadata, instanceConstraint)
+	findInstanceSpec(TestImageMetadata, instanceConstraint, false)
 
 	c.Check(instanceConstraint.Constraints.CpuPower, gc.IsNil)
 }
@@ -241,11 +242,123 @@ func (s *specSuite) TestFindInstanceSpecErrors(c *gc.C) {
 				Series:      t.series,
 				Arches:      t.arches,
 				Constraints: constraints.MustParse(t.cons),
-			})
+			}, false)
 		c.Check(err, gc.ErrorMatches, t.err)
 	}
 }
 
+func (s *specSuite) TestFindInstanceSpecInstanceStore(c *gc.C) {
+	imageMetadata := []*imagemetadata.ImageMetadata{
+		makeImage("ami-00000135", "ssd", "pv", "amd64", "16.04", "test"),
+		makeImage("ami-00000137", "instance", "pv", "amd64", "16.04", "test"),
+	}
+	spec, err := findInstanceSpec(imageMetadata, &instances.InstanceConstraint{
+		Region:      "test",
+		Series:      "xenial",
+		Arches:      []string{"amd64"},
+		Constraints: constraints.MustParse("instance-type=m3.medium"),
+		Storage:     []string{instanceStoreStorage},
+	}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.Image.Id, gc.Equals, "ami-00000137")
+}
+
+func (s *specSuite) TestFindInstanceSpecNoInstanceStoreImages(c *gc.C) {
+	imageMetadata := filterImageMetadata(c, TestImageMetadata, "xenial", []string{"amd64"})
+	_, err := findInstanceSpec(imageMetadata, &instances.InstanceConstraint{
+		Region:      "test",
+		Series:      "xenial",
+		Arches:      []string{"amd64"},
+		Constraints: constraints.MustParse("instance-type=m3.medium"),
+		Storage:     []string{instanceStoreStorage},
+	}, false)
+	c.Assert(err, gc.ErrorMatches, `no "xenial" images in test with root store instance`)
+}
+
+func (s *specSuite) TestFindInstanceSpecVirtType(c *gc.C) {
+	imageMetadata := []*imagemetadata.ImageMetadata{
+		makeImage("ami-00000135", "ssd", "pv", "amd64", "16.04", "test"),
+		makeImage("ami-00000136", "ssd", "hvm", "amd64", "16.04", "test"),
+	}
+	spec, err := findInstanceSpec(imageMetadata, &instances.InstanceConstraint{
+		Region:      "test",
+		Series:      "xenial",
+		Arches:      []string{"amd64"},
+		Constraints: constraints.MustParse("virt-type=hvm"),
+		Storage:     []string{ssdStorage},
+	}, false)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(spec.Image.Id, gc.Equals, "ami-00000136")
+	c.Assert(*spec.InstanceType.VirtType, gc.Equals, "hvm")
+}
+
+func (s *specSuite) TestFindInstanceSpecNoVirtTypeImages(c *gc.C) {
+	imageMetadata := []*imagemetadata.ImageMetadata{
+		makeImage("ami-00000135", "ssd", "pv", "amd64", "16.04", "test"),
+	}
+	_, err := findInstanceSpec(imageMetadata, &instances.InstanceConstraint{
+		Region:      "test",
+		Series:      "xenial",
+		Arches:      []string{"amd64"},
+		Constraints: constraints.MustParse("virt-type=hvm"),
+		Storage:     []string{ssdStorage},
+	}, false)
+	c.Assert(err, gc.ErrorMatches, `no "xenial" images in test with virtualization type hvm`)
+}
+
+func (s *specSuite) TestFindInstanceSpecSpot(c *gc.C) {
+	UseTestInstanceTypeData(instanceTypeCost{
+		"t2.micro":  5,
+		"t2.medium": 10,
+		"m3.medium": 95,
+	})
+	defer UseTestInstanceTypeData(TestInstanceTypeCosts)
+	imageMetadata := filterImageMetadata(c, TestImageMetadata, "xenial", []string{"amd64"})
+	findSpec := func(cons string, spot bool) *instances.InstanceSpec {
+		spec, err := findInstanceSpec(imageMetadata, &instances.InstanceConstraint{
+			Region:      "test",
+			Series:      "xenial",
+			Arches:      []string{"amd64"},
+			Constraints: constraints.MustParse(cons),
+			Storage:     []string{ssdStorage, ebsStorage},
+		}, spot)
+		c.Assert(err, jc.ErrorIsNil)
+		return spec
+	}
+
+	// With default constraints, the cheapest spot-capable type is chosen.
+	c.Assert(findSpec("", true).InstanceType.Name, gc.Equals, "m3.medium")
+
+	// Burstable types are cheapest when cpu-power allows them, but
+	// are never chosen for spot instances.
+	c.Assert(findSpec("cpu-power=0", false).InstanceType.Name, gc.Equals, "t2.micro")
+	c.Assert(findSpec("cpu-power=0", true).InstanceType.Name, gc.Equals, "m3.medium")
+}
+
+func (s *specSuite) TestFindInstanceSpecSpotInstanceType(c *gc.C) {
+	_, err := findInstanceSpec(TestImageMetadata, &instances.InstanceConstraint{
+		Region:      "test",
+		Series:      "xenial",
+		Arches:      []string{"amd64"},
+		Constraints: constraints.MustParse("instance-type=t2.micro"),
+	}, true)
+	c.Assert(err, gc.ErrorMatches, `instance type "t2.micro" cannot be run as a spot instance`)
+}
+
+func (s *specSuite) TestFindInstanceSpecVirtTypeInstanceTypeMismatch(c *gc.C) {
+	imageMetadata := []*imagemetadata.ImageMetadata{
+		makeImage("ami-00000136", "ssd", "hvm", "amd64", "16.04", "test"),
+	}
+	_, err := findInstanceSpec(imageMetadata, &instances.InstanceConstraint{
+		Region:      "test",
+		Series:      "xenial",
+		Arches:      []string{"amd64"},
+		Constraints: constraints.MustParse("instance-type=m3.medium virt-type=hvm"),
+		Storage:     []string{ssdStorage},
+	}, false)
+	c.Assert(err, gc.ErrorMatches, `instance type "m3.medium" requires pv images, but virt-type is "hvm"`)
+}
+
 func filterImageMetadata(
 	c *gc.C,
 	in []*imagemetadata.ImageMetadata,
@@ -292,3 +405,53 @@ func (*specSuite) TestFilterImagesMaintainsOrdering(c *gc.C) {
 	ic := &instances.InstanceConstraint{Storage: []string{"ebs"}}
 	c.Check(filterImages(input, ic), gc.DeepEquals, input)
 }
+
+func (s *specSuite) TestArchForInstanceType(c *gc.C) {
+	for _, test := range []struct {
+		instanceType string
+		arch         string
+	}{
+		{"m1.small", "amd64"},
+		{"m4.large", "amd64"},
+	} {
+		arch, err := ArchForInstanceType(test.instanceType)
+		c.Check(err, jc.ErrorIsNil)
+		c.Check(arch, gc.Equals, test.arch)
+	}
+}
+
+func (s *specSuite) TestArchForInstanceTypeUnknown(c *gc.C) {
+	_, err := ArchForInstanceType("m9.enormous")
+	c.Assert(err, jc.Satisfies, errors.IsNotFound)
+	c.Assert(err, gc.ErrorMatches, `instance type "m9.enormous" not found`)
+}
+
+func (s *specSuite) TestInstanceTypes(c *gc.C) {
+	specs, err := InstanceTypes("test")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(specs, gc.HasLen, len(TestInstanceTypeCosts))
+	byName := make(map[string]InstanceTypeSpec)
+	for _, spec := range specs {
+		byName[spec.Name] = spec
+	}
+	c.Assert(byName["m1.small"], jc.DeepEquals, InstanceTypeSpec{
+		Name:     "m1.small",
+		CpuCores: 1,
+		Mem:      1740,
+		Arches:   []string{"amd64", "i386"},
+		VirtType: "pv",
+		Cost:     60,
+		Spot:     true,
+	})
+	c.Assert(byName["cc2.8xlarge"].VirtType, gc.Equals, "hvm")
+}
+
+func (s *specSuite) TestInstanceTypesUnknownRegion(c *gc.C) {
+	_, err := InstanceTypes("mars-north-1")
+	c.Assert(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (s *specSuite) TestSpotAvailable(c *gc.C) {
+	c.Assert(spotAvailable("m3.medium"), jc.IsTrue)
+	c.Assert(spotAvailable("t2.micro"), jc.IsFalse)
+}
@@ -20,6 +20,38 @@ type ec2Instance struct {
 	*ec2.Instance
 }
 
+// ImageInfo records the machine image an instance was started from.
+type ImageInfo struct {
+	// ImageId is the ID of the AMI.
+	ImageId string
+
+	// VirtType is the virtualization type of the image, such as
+	// "hvm" or "paravirtual".
+	VirtType string
+
+	// RootDeviceType is the type of the image's root device,
+	// such as "ebs" or "instance-store".
+	RootDeviceType string
+}
+
+// HasImageInfo is implemented by the instances returned by the ec2
+// provider's StartInstance and Instances methods, so that callers can
+// tell which image an instance was started from.
+type HasImageInfo interface {
+	ImageInfo() ImageInfo
+}
+
+var _ HasImageInfo = (*ec2Instance)(nil)
+
+// ImageInfo is specified on the HasImageInfo interface.
+func (inst *ec2Instance) ImageInfo() ImageInfo {
+	return ImageInfo{
+		ImageId:        inst.ImageId,
+		VirtType:       inst.VirtType,
+		RootDeviceType: inst.RootDeviceType,
+	}
+}
+
 func (inst *ec2Instance) String() string {
 	return string(inst.Id())
 }
@@ -50,8 +82,21 @@ func (inst *ec2Instance) Status() instance.InstanceStatus {
 
 }
 
+// DNSName returns the public DNS name of the instance, or the empty
+// string if it has none.
+func (inst *ec2Instance) DNSName() string {
+	return inst.Instance.DNSName
+}
+
+// PrivateDNSName returns the DNS name by which the instance is known
+// within its VPC or the EC2 network, or the empty string if it has none.
+func (inst *ec2Instance) PrivateDNSName() string {
+	return inst.Instance.PrivateDNSName
+}
+
 // Addresses implements network.Addresses() returning generic address
 // details for the instance, and requerying the ec2 api if required.
+// The IP addresses are listed before the DNS names.
 func (inst *ec2Instance) Addresses() ([]network.Address, error) {
 	var addresses []network.Address
 	possibleAddresses := []network.Address{
@@ -65,6 +110,16 @@ func (inst *ec2Instance) Addresses() ([]network.Address, error) {
 			Type:  network.IPv4Address,
 			Scope: network.ScopeCloudLocal,
 		},
+		{
+			Value: inst.DNSName(),
+			Type:  network.HostName,
+			Scope: network.ScopePublic,
+		},
+		{
+			Value: inst.PrivateDNSName(),
+			Type:  network.HostName,
+			Scope: network.ScopeCloudLocal,
+		},
 	}
 	for _, address := range possibleAddresses {
 		if address.Value != "" {
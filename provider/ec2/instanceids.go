@@ -0,0 +1,53 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"github.com/juju/errors"
+	"gopkg.in/amz.v3/ec2"
+)
+
+// InstanceIdValidator checks the instance ids returned by
+// DescribeInstances before the provider hands them on to juju.
+type InstanceIdValidator interface {
+	// ValidateInstanceIds returns an error if the given ids, in the
+	// order they were returned, cannot be used to identify instances.
+	ValidateInstanceIds(ids []string) error
+}
+
+// UniqueInstanceIds is an InstanceIdValidator that rejects empty and
+// duplicated instance ids.
+type UniqueInstanceIds struct{}
+
+// ValidateInstanceIds is part of the InstanceIdValidator interface.
+func (UniqueInstanceIds) ValidateInstanceIds(ids []string) error {
+	seen := make(map[string]bool)
+	for _, id := range ids {
+		if id == "" {
+			return errors.NotValidf("empty instance id")
+		}
+		if seen[id] {
+			return errors.NotValidf("duplicate instance id %q", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// instanceIdValidator is used to check the instance ids in every
+// DescribeInstances response that the environ turns into instances.
+var instanceIdValidator InstanceIdValidator = UniqueInstanceIds{}
+
+// validateInstancesResp checks the instance ids in resp with
+// instanceIdValidator.
+func validateInstancesResp(resp *ec2.InstancesResp) error {
+	var ids []string
+	for _, r := range resp.Reservations {
+		for _, inst := range r.Instances {
+			ids = append(ids, inst.InstanceId)
+		}
+	}
+	err := instanceIdValidator.ValidateInstanceIds(ids)
+	return errors.Annotate(err, "DescribeInstances returned bad instance ids")
+}
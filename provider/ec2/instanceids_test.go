@@ -0,0 +1,37 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2_test
+
+import (
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/provider/ec2"
+	"github.com/juju/juju/testing"
+)
+
+type instanceIdsSuite struct {
+	testing.BaseSuite
+}
+
+var _ = gc.Suite(&instanceIdsSuite{})
+
+func (s *instanceIdsSuite) TestUniqueInstanceIds(c *gc.C) {
+	var v ec2.InstanceIdValidator = ec2.UniqueInstanceIds{}
+	c.Assert(v.ValidateInstanceIds(nil), jc.ErrorIsNil)
+	c.Assert(v.ValidateInstanceIds([]string{"i-1", "i-2"}), jc.ErrorIsNil)
+}
+
+func (s *instanceIdsSuite) TestUniqueInstanceIdsEmpty(c *gc.C) {
+	err := ec2.UniqueInstanceIds{}.ValidateInstanceIds([]string{"i-1", ""})
+	c.Assert(err, gc.ErrorMatches, "empty instance id not valid")
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
+
+func (s *instanceIdsSuite) TestUniqueInstanceIdsDuplicate(c *gc.C) {
+	err := ec2.UniqueInstanceIds{}.ValidateInstanceIds([]string{"i-1", "i-2", "i-1"})
+	c.Assert(err, gc.ErrorMatches, `duplicate instance id "i-1" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+}
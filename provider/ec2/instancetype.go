@@ -4,6 +4,9 @@
 package ec2
 
 import (
+	"strings"
+
+	"github.com/juju/errors"
 	"gopkg.in/amz.v3/aws"
 
 	"github.com/juju/juju/environs/instances"
@@ -21,6 +24,96 @@ var (
 	both  = []string{arch.AMD64, arch.I386}
 )
 
+// ArchForInstanceType returns the architecture of the images that
+// instances of the named type run. Where a type can run images of
+// more than one architecture, amd64 is preferred. An error satisfying
+// errors.IsNotFound is returned for unknown instance types.
+func ArchForInstanceType(instanceType string) (string, error) {
+	for _, itype := range allInstanceTypes {
+		if itype.Name == instanceType {
+			return itype.Arches[0], nil
+		}
+	}
+	return "", errors.NotFoundf("instance type %q", instanceType)
+}
+
+// InstanceTypeSpec describes an instance type that is available in
+// a region.
+type InstanceTypeSpec struct {
+	// Name holds the name of the instance type, such as "m3.medium".
+	Name string
+
+	// CpuCores holds the number of CPU cores.
+	CpuCores uint64
+
+	// Mem holds the memory size in MB.
+	Mem uint64
+
+	// Arches holds the architectures of the images the instance
+	// type can run, in order of preference.
+	Arches []string
+
+	// VirtType holds the virtualization type of the images the
+	// instance type can run, "hvm" or "pv".
+	VirtType string
+
+	// Cost holds the cost of the instance type in the region,
+	// in USDe-3/hour.
+	Cost uint64
+
+	// Spot reports whether instances of the type can be run as spot
+	// instances, as they are when spot-price is set.
+	Spot bool
+}
+
+// InstanceTypes returns the instance types that juju can start in the
+// given region, in no particular order. An error satisfying
+// errors.IsNotSupported is returned if there is no instance type data
+// for the region.
+func InstanceTypes(region string) ([]InstanceTypeSpec, error) {
+	if err := checkRegionSupported(region); err != nil {
+		return nil, errors.Trace(err)
+	}
+	itypes := regionInstanceTypes(region)
+	specs := make([]InstanceTypeSpec, len(itypes))
+	for i, itype := range itypes {
+		specs[i] = InstanceTypeSpec{
+			Name:     itype.Name,
+			CpuCores: itype.CpuCores,
+			Mem:      itype.Mem,
+			Arches:   itype.Arches,
+			Cost:     itype.Cost,
+			Spot:     spotAvailable(itype.Name),
+		}
+		if itype.VirtType != nil {
+			specs[i].VirtType = *itype.VirtType
+		}
+	}
+	return specs, nil
+}
+
+// regionInstanceTypes returns the known instance types that are
+// available in the given region, with their costs there filled in.
+func regionInstanceTypes(region string) []instances.InstanceType {
+	costs := allRegionCosts[region]
+	var itypes []instances.InstanceType
+	for _, itype := range allInstanceTypes {
+		cost, ok := costs[itype.Name]
+		if !ok {
+			continue
+		}
+		itype.Cost = cost
+		itypes = append(itypes, itype)
+	}
+	return itypes
+}
+
+// spotAvailable reports whether instances of the named type can be
+// run as spot instances. Burstable performance (t2) instances cannot.
+func spotAvailable(instanceType string) bool {
+	return !strings.HasPrefix(instanceType, "t2.")
+}
+
 // allRegions is defined here to allow tests to override the content.
 var allRegions = aws.Regions
 
@@ -9,14 +9,15 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/juju/errors"
+	jujutesting "github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
 	"github.com/juju/utils"
 	"github.com/juju/utils/arch"
 	"github.com/juju/utils/clock"
 	"github.com/juju/utils/series"
-	"github.com/juju/utils/set"
 	"github.com/juju/utils/ssh"
 	"gopkg.in/amz.v3/aws"
 	amzec2 "gopkg.in/amz.v3/ec2"
@@ -27,6 +28,8 @@ import (
 	goyaml "gopkg.in/yaml.v2"
 
 	"github.com/juju/juju/cloud"
+	"github.com/juju/juju/cloudconfig/cloudinit/cloudinittest"
+	"github.com/juju/juju/cloudconfig/instancecfg"
 	"github.com/juju/juju/constraints"
 	"github.com/juju/juju/environs"
 	"github.com/juju/juju/environs/bootstrap"
@@ -48,6 +51,7 @@ import (
 	"github.com/juju/juju/storage"
 	coretesting "github.com/juju/juju/testing"
 	jujuversion "github.com/juju/juju/version"
+	"github.com/juju/juju/worker/workertest"
 )
 
 var localConfigAttrs = coretesting.FakeConfig().Merge(coretesting.Attrs{
@@ -339,6 +343,53 @@ func (t *localServerSuite) TestPrepareForBootstrapWithDefaultVPCID(c *gc.C) {
 	t.prepareWithParamsAndBootstrapWithVPCID(c, params, t.srv.defaultVPC.Id)
 }
 
+func (t *localServerSuite) TestPrepareForBootstrapWithUnsupportedRegion(c *gc.C) {
+	// The "test" region is known to goamz, but no longer has any
+	// instance type data.
+	ec2.UseTestInstanceTypeData(nil)
+	defer ec2.UseTestInstanceTypeData(ec2.TestInstanceTypeCosts)
+
+	expectedError := `region "test" is not supported by this version of juju; please upgrade juju to use it`
+	err := t.AssertPrepareFailsWithConfig(c, coretesting.Attrs{}, expectedError)
+	c.Check(err, jc.Satisfies, errors.IsNotSupported)
+}
+
+func (t *localServerSuite) TestPrepareForBootstrapWithUnknownSubnetID(c *gc.C) {
+	unknownSubnetIDConfig := coretesting.Attrs{
+		"vpc-id":    t.srv.defaultVPC.Id,
+		"subnet-id": "subnet-unknown",
+	}
+
+	expectedError := `subnet "subnet-unknown" in VPC ".*" not found`
+	err := t.AssertPrepareFailsWithConfig(c, unknownSubnetIDConfig, expectedError)
+	c.Check(err, jc.Satisfies, errors.IsNotFound)
+}
+
+func (t *localServerSuite) TestStartInstanceWithSubnetID(c *gc.C) {
+	subnet, err := t.srv.ec2srv.AddSubnet(amzec2.Subnet{
+		VPCId:     t.srv.defaultVPC.Id,
+		CIDRBlock: "10.10.99.0/24",
+		AvailZone: "test-available",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	params := t.PrepareParams(c)
+	params.ModelConfig["vpc-id"] = t.srv.defaultVPC.Id
+	params.ModelConfig["subnet-id"] = subnet.Id
+	env := t.PrepareWithParams(c, params)
+	err = bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	inst, _ := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	ec2inst := ec2.InstanceEC2(inst)
+	c.Check(ec2inst.SubnetId, gc.Equals, subnet.Id)
+	c.Check(ec2inst.AvailZone, gc.Equals, "test-available")
+}
+
 func (t *localServerSuite) TestSystemdBootstrapInstanceUserDataAndState(c *gc.C) {
 	env := t.Prepare(c)
 	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
@@ -544,6 +595,65 @@ func (t *localServerSuite) TestDestroyErr(c *gc.C) {
 	c.Assert(errors.Cause(err).Error(), jc.Contains, msg)
 }
 
+func (t *localServerSuite) TestDestroyTerminateTimeout(c *gc.C) {
+	// The test server leaves terminated instances shutting-down,
+	// just as EBS-backed instances sometimes linger in EC2. The
+	// spice instances are not the model's, and must not be waited
+	// for or reported.
+	t.srv.addSpice(c)
+	t.BaseSuite.PatchValue(ec2.TerminatePollDelay, 10*time.Millisecond)
+	params := t.PrepareParams(c)
+	params.ModelConfig["terminate-timeout"] = "50ms"
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	insts, err := env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(insts, gc.HasLen, 2)
+	ids := []string{string(insts[0].Id()), string(insts[1].Id())}
+	sort.Strings(ids)
+
+	err = env.Destroy()
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		"instances not terminated after 50ms: %s, %s", ids[0], ids[1],
+	))
+
+	// The instances were asked to terminate all the same.
+	terminated, err := ec2.TerminatedInstances(env)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(terminated, gc.HasLen, 2)
+}
+
+func (t *localServerSuite) TestWaitForInstanceState(c *gc.C) {
+	env := t.prepareAndBootstrap(c)
+	inst, _ := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	strategy := utils.AttemptStrategy{Total: time.Second, Delay: 10 * time.Millisecond}
+
+	err := ec2.WaitForInstanceState(env, inst.Id(), amzec2.InstanceState{Name: "running"}, strategy)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = env.StopInstances(inst.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	err = ec2.WaitForInstanceState(env, inst.Id(), amzec2.InstanceState{Name: "running"}, strategy)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(`instance %q is shutting-down`, inst.Id()))
+}
+
+func (t *localServerSuite) TestWaitForInstanceStateTimeout(c *gc.C) {
+	env := t.prepareAndBootstrap(c)
+	inst, _ := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	strategy := utils.AttemptStrategy{Total: 50 * time.Millisecond, Delay: 10 * time.Millisecond}
+
+	err := ec2.WaitForInstanceState(env, inst.Id(), amzec2.InstanceState{Name: "stopped"}, strategy)
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(
+		`timed out waiting for instance %q to be stopped \(state "running"\)`, inst.Id(),
+	))
+}
+
 func (t *localServerSuite) TestGetTerminatedInstances(c *gc.C) {
 	env := t.Prepare(c)
 	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
@@ -573,6 +683,39 @@ func (t *localServerSuite) TestGetTerminatedInstances(c *gc.C) {
 	c.Assert(terminated[0].Id(), jc.DeepEquals, inst1.Id())
 }
 
+func (t *localServerSuite) TestListManagedResources(c *gc.C) {
+	env := t.prepareAndBootstrap(c)
+	inst1, _ := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+
+	insts, err := env.AllInstances()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(insts, gc.HasLen, 2)
+	var expect []ec2.Resource
+	for _, inst := range insts {
+		expect = append(expect, ec2.Resource{Type: ec2.ResourceInstance, Id: string(inst.Id())})
+	}
+	groupsResp, err := t.srv.client.SecurityGroups(nil, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, group := range groupsResp.Groups {
+		if strings.HasPrefix(group.Name, ec2.JujuGroupName(env)) {
+			expect = append(expect, ec2.Resource{Type: ec2.ResourceSecurityGroup, Id: group.Id})
+		}
+	}
+
+	resources, err := ec2.ListManagedResources(env)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(resources, jc.SameContents, expect)
+
+	// Stopped instances are no longer reported.
+	err = env.StopInstances(inst1.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	resources, err = ec2.ListManagedResources(env)
+	c.Assert(err, jc.ErrorIsNil)
+	for _, r := range resources {
+		c.Check(r, gc.Not(gc.Equals), ec2.Resource{Type: ec2.ResourceInstance, Id: string(inst1.Id())})
+	}
+}
+
 func (t *localServerSuite) TestInstanceSecurityGroupsWitheInstanceStatusFilter(c *gc.C) {
 	env := t.prepareAndBootstrap(c)
 
@@ -736,6 +879,85 @@ func (t *localServerSuite) TestInstanceStatus(c *gc.C) {
 	c.Assert(inst.Status().Message, gc.Equals, "terminated")
 }
 
+func (t *localServerSuite) TestInstancesExcludesDeadInstances(c *gc.C) {
+	t.PatchValue(ec2.ShortAttempt, utils.AttemptStrategy{})
+	env := t.Prepare(c)
+	modelTag := amzec2.Tag{Key: tags.JujuModel, Value: env.Config().UUID()}
+	var dead []instance.Id
+	for _, state := range []amzec2.InstanceState{ec2test.ShuttingDown, ec2test.Terminated, ec2test.Stopped} {
+		ids := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", state, nil)
+		_, err := ec2.EnvironEC2(env).CreateTags(ids, []amzec2.Tag{modelTag})
+		c.Assert(err, jc.ErrorIsNil)
+		dead = append(dead, instance.Id(ids[0]))
+	}
+	running := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", ec2test.Running, nil)
+	_, err := ec2.EnvironEC2(env).CreateTags(running, []amzec2.Tag{modelTag})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = env.Instances(dead)
+	c.Assert(err, gc.Equals, environs.ErrNoInstances)
+
+	insts, err := env.Instances(append([]instance.Id{instance.Id(running[0])}, dead...))
+	c.Assert(err, gc.Equals, environs.ErrPartialInstances)
+	c.Assert(insts, gc.HasLen, 4)
+	c.Assert(insts[0].Id(), gc.Equals, instance.Id(running[0]))
+	for _, inst := range insts[1:] {
+		c.Assert(inst, gc.IsNil)
+	}
+}
+
+func (t *localServerSuite) TestWatchInstanceStatus(c *gc.C) {
+	t.PatchValue(ec2.ShortAttempt, utils.AttemptStrategy{})
+	env := t.Prepare(c)
+	modelTag := amzec2.Tag{Key: tags.JujuModel, Value: env.Config().UUID()}
+	ids := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", ec2test.Running, nil)
+	_, err := ec2.EnvironEC2(env).CreateTags(ids, []amzec2.Tag{modelTag})
+	c.Assert(err, jc.ErrorIsNil)
+
+	clock := jujutesting.NewClock(time.Time{})
+	watcher, ok := env.(environs.InstanceStatusWatcher)
+	c.Assert(ok, jc.IsTrue)
+	w, err := watcher.WatchInstanceStatus(clock, []instance.Id{instance.Id(ids[0])})
+	c.Assert(err, jc.ErrorIsNil)
+	defer workertest.DirtyKill(c, w)
+
+	nextResult := func() environs.InstancesResult {
+		select {
+		case result, ok := <-w.Changes():
+			c.Assert(ok, jc.IsTrue)
+			return result
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for instance status")
+		}
+		panic("unreachable")
+	}
+	waitPoll := func() {
+		select {
+		case <-clock.Alarms():
+		case <-time.After(coretesting.LongWait):
+			c.Fatalf("timed out waiting for poll")
+		}
+	}
+
+	result := nextResult()
+	c.Assert(result.Err, jc.ErrorIsNil)
+	c.Assert(result.Instances, gc.HasLen, 1)
+	c.Assert(result.Instances[0].Id(), gc.Equals, instance.Id(ids[0]))
+
+	// Terminating the instance is reported on the next poll.
+	waitPoll()
+	_, err = ec2.EnvironEC2(env).TerminateInstances(ids)
+	c.Assert(err, jc.ErrorIsNil)
+	clock.Advance(*ec2.InstanceStatusPollInterval)
+	result = nextResult()
+	c.Assert(result.Err, gc.Equals, environs.ErrNoInstances)
+
+	// Stopping the watcher closes its channel.
+	workertest.CleanKill(c, w)
+	_, ok = <-w.Changes()
+	c.Assert(ok, jc.IsFalse)
+}
+
 func (t *localServerSuite) TestStartInstanceHardwareCharacteristics(c *gc.C) {
 	env := t.prepareAndBootstrap(c)
 	_, hc := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
@@ -745,6 +967,26 @@ func (t *localServerSuite) TestStartInstanceHardwareCharacteristics(c *gc.C) {
 	c.Assert(*hc.CpuPower, gc.Equals, uint64(300))
 }
 
+func (t *localServerSuite) TestStartInstanceImageInfo(c *gc.C) {
+	var imageIds []string
+	t.PatchValue(ec2.RunInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances) (*amzec2.RunInstancesResp, error) {
+		imageIds = append(imageIds, ri.ImageId)
+		return e.RunInstances(ri)
+	})
+	env := t.prepareAndBootstrap(c)
+	inst, _ := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	c.Assert(imageIds, gc.HasLen, 2)
+
+	info := inst.(ec2.HasImageInfo).ImageInfo()
+	c.Assert(info.ImageId, gc.Equals, imageIds[1])
+	c.Assert(info.VirtType, gc.Not(gc.Equals), "")
+
+	// The image is still known when the instance is looked up again.
+	insts, err := env.Instances([]instance.Id{inst.Id()})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(insts[0].(ec2.HasImageInfo).ImageInfo().ImageId, gc.Equals, imageIds[1])
+}
+
 func (t *localServerSuite) TestStartInstanceAvailZone(c *gc.C) {
 	inst, err := t.testStartInstanceAvailZone(c, "test-available")
 	c.Assert(err, jc.ErrorIsNil)
@@ -758,7 +1000,7 @@ func (t *localServerSuite) TestStartInstanceAvailZoneImpaired(c *gc.C) {
 
 func (t *localServerSuite) TestStartInstanceAvailZoneUnknown(c *gc.C) {
 	_, err := t.testStartInstanceAvailZone(c, "test-unknown")
-	c.Assert(err, gc.ErrorMatches, `invalid availability zone "test-unknown"`)
+	c.Assert(err, gc.ErrorMatches, `invalid availability zone "test-unknown"; valid zones for region "test" are: test-available, test-impaired, test-unavailable`)
 }
 
 func (t *localServerSuite) testStartInstanceAvailZone(c *gc.C, zone string) (instance.Instance, error) {
@@ -963,6 +1205,483 @@ func (t *localServerSuite) testStartInstanceAvailZoneAllConstrained(c *gc.C, run
 	c.Assert(azArgs, gc.DeepEquals, []string{"az1", "az2"})
 }
 
+func (t *localServerSuite) TestStartInstanceSpotPrice(c *gc.C) {
+	var spotPrices []string
+	t.PatchValue(ec2.RequestSpotInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances, price string) (*amzec2.RunInstancesResp, error) {
+		spotPrices = append(spotPrices, price)
+		return e.RunInstances(ri)
+	})
+
+	params := t.PrepareParams(c)
+	params.ModelConfig["spot-price"] = "0.05"
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	// The controller must not be provisioned as a spot instance.
+	c.Assert(spotPrices, gc.HasLen, 0)
+
+	testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	c.Assert(spotPrices, jc.DeepEquals, []string{"0.05"})
+}
+
+func (t *localServerSuite) TestSpotPriceHistory(c *gc.C) {
+	since := time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC)
+	var requests []*amzec2.DescribeSpotPriceHistory
+	t.PatchValue(ec2.DescribeSpotPriceHistory, func(e *amzec2.EC2, req *amzec2.DescribeSpotPriceHistory) (*amzec2.DescribeSpotPriceHistoryResp, error) {
+		requests = append(requests, req)
+		return &amzec2.DescribeSpotPriceHistoryResp{
+			History: []amzec2.SpotPriceHistory{{
+				InstanceType:       "m3.medium",
+				AvailabilityZone:   "test-available",
+				ProductDescription: "Linux/UNIX",
+				SpotPrice:          "0.0110",
+				Timestamp:          since.Add(2 * time.Hour),
+			}, {
+				InstanceType:       "m3.medium",
+				AvailabilityZone:   "test-available",
+				ProductDescription: "Linux/UNIX",
+				SpotPrice:          "0.0100",
+				Timestamp:          since.Add(time.Hour),
+			}},
+		}, nil
+	})
+
+	// Spot prices are available whether or not spot-price is set.
+	env := t.Prepare(c)
+	prices, err := ec2.SpotPriceHistory(env, "m3.medium", "test-available", since)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(requests, gc.HasLen, 1)
+	c.Assert(requests[0].InstanceType, jc.DeepEquals, []string{"m3.medium"})
+	c.Assert(requests[0].AvailabilityZone, gc.Equals, "test-available")
+	c.Assert(requests[0].StartTime, gc.Equals, since)
+	c.Assert(prices, jc.DeepEquals, []ec2.SpotPrice{{
+		InstanceType:       "m3.medium",
+		AvailabilityZone:   "test-available",
+		ProductDescription: "Linux/UNIX",
+		Price:              "0.0100",
+		Timestamp:          since.Add(time.Hour),
+	}, {
+		InstanceType:       "m3.medium",
+		AvailabilityZone:   "test-available",
+		ProductDescription: "Linux/UNIX",
+		Price:              "0.0110",
+		Timestamp:          since.Add(2 * time.Hour),
+	}})
+}
+
+func (t *localServerSuite) TestSpotPriceHistoryError(c *gc.C) {
+	t.PatchValue(ec2.DescribeSpotPriceHistory, func(e *amzec2.EC2, req *amzec2.DescribeSpotPriceHistory) (*amzec2.DescribeSpotPriceHistoryResp, error) {
+		return nil, errors.New("boom")
+	})
+	env := t.Prepare(c)
+	_, err := ec2.SpotPriceHistory(env, "m3.medium", "", time.Time{})
+	c.Assert(err, gc.ErrorMatches, `fetching spot price history for "m3.medium": boom`)
+}
+
+type instanceIdValidatorFunc func([]string) error
+
+func (f instanceIdValidatorFunc) ValidateInstanceIds(ids []string) error {
+	return f(ids)
+}
+
+func (t *localServerSuite) TestBootstrapAdoptInstance(c *gc.C) {
+	ids := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", ec2test.Running, nil)
+	t.PatchValue(ec2.CheckProvisioned, func(host string) (bool, error) {
+		return false, nil
+	})
+	t.PatchValue(ec2.DetectSeriesAndHardware, func(host string) (instance.HardwareCharacteristics, string, error) {
+		amd64 := arch.AMD64
+		return instance.HardwareCharacteristics{Arch: &amd64}, series.LatestLts(), nil
+	})
+	var configuredHost string
+	t.PatchValue(ec2.ConfigureMachine, func(
+		ctx environs.BootstrapContext, client ssh.Client, host string, icfg *instancecfg.InstanceConfig,
+	) error {
+		configuredHost = host
+		c.Check(icfg.Bootstrap.BootstrapMachineInstanceId, gc.Equals, instance.Id(ids[0]))
+		return nil
+	})
+
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+		Placement:        "instance-id=" + ids[0],
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(configuredHost, gc.Not(gc.Equals), "")
+
+	// The adopted instance is now the controller instance.
+	controllers, err := env.ControllerInstances(t.ControllerUUID)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(controllers, jc.DeepEquals, []instance.Id{instance.Id(ids[0])})
+}
+
+func (t *localServerSuite) TestBootstrapAdoptInstanceNotRunning(c *gc.C) {
+	ids := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", ec2test.Stopped, nil)
+	t.PatchValue(ec2.CheckProvisioned, func(host string) (bool, error) {
+		c.Fatalf("unexpected SSH connection to %s", host)
+		return false, nil
+	})
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+		Placement:        "instance-id=" + ids[0],
+	})
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(`.*cannot adopt instance %q: instance is stopped, not running`, ids[0]))
+}
+
+func (t *localServerSuite) TestBootstrapAdoptInstanceProvisioned(c *gc.C) {
+	ids := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", ec2test.Running, nil)
+	t.PatchValue(ec2.CheckProvisioned, func(host string) (bool, error) {
+		return true, nil
+	})
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+		Placement:        "instance-id=" + ids[0],
+	})
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(`.*cannot adopt instance %q: machine is already provisioned`, ids[0]))
+}
+
+func (t *localServerSuite) TestBootstrapAdoptInstanceUnknownArch(c *gc.C) {
+	ids := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", ec2test.Running, nil)
+	t.PatchValue(ec2.CheckProvisioned, func(host string) (bool, error) {
+		return false, nil
+	})
+	t.PatchValue(ec2.DetectSeriesAndHardware, func(host string) (instance.HardwareCharacteristics, string, error) {
+		return instance.HardwareCharacteristics{}, series.LatestLts(), nil
+	})
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+		Placement:        "instance-id=" + ids[0],
+	})
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(`.*cannot adopt instance %q: cannot detect its architecture`, ids[0]))
+}
+
+func (t *localServerSuite) TestBootstrapAdoptInstanceOutsideModelVPC(c *gc.C) {
+	ids := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", ec2test.Running, nil)
+	t.PatchValue(ec2.CheckProvisioned, func(host string) (bool, error) {
+		c.Fatalf("unexpected SSH connection to %s", host)
+		return false, nil
+	})
+	vpc := t.srv.ec2srv.AddVPC(amzec2.VPC{CIDRBlock: "10.20.0.0/16"})
+	params := t.PrepareParams(c)
+	params.ModelConfig["vpc-id"] = vpc.Id
+	params.ModelConfig["vpc-id-force"] = true
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+		Placement:        "instance-id=" + ids[0],
+	})
+	c.Assert(err, gc.ErrorMatches, fmt.Sprintf(`.*cannot adopt instance %q: instance is in VPC ".*", not the model's VPC %q`, ids[0], vpc.Id))
+}
+
+func (t *localServerSuite) TestBootstrapAdoptInstanceInvalidId(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+		Placement:        "instance-id=foo",
+	})
+	c.Assert(err, gc.ErrorMatches, `.*instance id "foo" in placement "instance-id=foo" not valid`)
+}
+
+func (t *localServerSuite) TestAllInstancesValidatesInstanceIds(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	var validated []string
+	t.PatchValue(ec2.InstanceIdValidatorVar, instanceIdValidatorFunc(func(ids []string) error {
+		validated = append(validated, ids...)
+		return errors.NotValidf("duplicate instance id %q", ids[0])
+	}))
+	_, err = env.AllInstances()
+	c.Assert(err, gc.ErrorMatches, `DescribeInstances returned bad instance ids: duplicate instance id "i-.*" not valid`)
+	c.Assert(err, jc.Satisfies, errors.IsNotValid)
+	c.Assert(validated, gc.HasLen, 1)
+}
+
+func (t *localServerSuite) TestStartInstanceExtraSecurityGroups(c *gc.C) {
+	params := t.PrepareParams(c)
+	params.ModelConfig["extra-security-groups"] = "monitoring"
+	env := t.PrepareWithParams(c, params)
+	resp, err := ec2.EnvironEC2(env).CreateSecurityGroup("", "monitoring", "monitoring access")
+	c.Assert(err, jc.ErrorIsNil)
+	monitoring := resp.SecurityGroup
+
+	err = bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	inst, _ := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	groups := ec2.InstanceEC2(inst).SecurityGroups
+	c.Assert(groups, gc.HasLen, 3)
+	c.Assert(groups[2].Id, gc.Equals, monitoring.Id)
+
+	// The extra group is left alone when the instance is stopped.
+	err = env.StopInstances(inst.Id())
+	c.Assert(err, jc.ErrorIsNil)
+	groupsResp, err := ec2.EnvironEC2(env).SecurityGroups([]amzec2.SecurityGroup{{Id: monitoring.Id}}, nil)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(groupsResp.Groups, gc.HasLen, 1)
+}
+
+func (t *localServerSuite) TestBootstrapExtraSecurityGroupsMissing(c *gc.C) {
+	params := t.PrepareParams(c)
+	params.ModelConfig["extra-security-groups"] = "sg-missing,monitoring"
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, gc.ErrorMatches, "extra security groups sg-missing, monitoring not found")
+}
+
+func (t *localServerSuite) TestBootstrapTwice(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, gc.ErrorMatches, "found controller instances .*: model is already bootstrapped")
+	c.Assert(environs.IsAlreadyBootstrapped(err), jc.IsTrue)
+}
+
+func (t *localServerSuite) TestBootstrapStoppedControllerInstance(c *gc.C) {
+	env := t.Prepare(c)
+	controllerConfig := coretesting.FakeControllerConfig()
+	inst := t.srv.ec2srv.NewInstances(1, "m1.small", "ami-a7f539ce", ec2test.Stopped, nil)
+	_, err := ec2.EnvironEC2(env).CreateTags(inst, []amzec2.Tag{
+		{Key: tags.JujuIsController, Value: "true"},
+		{Key: tags.JujuController, Value: controllerConfig.ControllerUUID()},
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	// A stopped controller instance prevents bootstrap by default...
+	err = bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: controllerConfig,
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(environs.IsAlreadyBootstrapped(err), jc.IsTrue)
+
+	// ...but not when forced.
+	err = bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: controllerConfig,
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+		Force:            true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (t *localServerSuite) TestStartInstanceRootDiskConfig(c *gc.C) {
+	params := t.PrepareParams(c)
+	params.ModelConfig["root-disk"] = 16384
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, hc := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	c.Check(*hc.RootDisk, gc.Equals, uint64(16384))
+
+	// The root-disk constraint takes precedence over the config.
+	cons := constraints.MustParse("root-disk=20G")
+	_, hc = testing.AssertStartInstanceWithConstraints(c, env, t.ControllerUUID, "2", cons)
+	c.Check(*hc.RootDisk, gc.Equals, uint64(20480))
+}
+
+func (t *localServerSuite) TestStartInstanceRootDiskConfigTooSmall(c *gc.C) {
+	params := t.PrepareParams(c)
+	params.ModelConfig["root-disk"] = 1024
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, gc.ErrorMatches, `.*root-disk of 1024M is smaller than the EC2 image size of 8192M`)
+}
+
+func (t *localServerSuite) TestStartInstanceIAMInstanceProfile(c *gc.C) {
+	var profiles []string
+	t.PatchValue(ec2.RunInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances) (*amzec2.RunInstancesResp, error) {
+		profiles = append(profiles, ri.IAMInstanceProfile)
+		return e.RunInstances(ri)
+	})
+
+	params := t.PrepareParams(c)
+	params.ModelConfig["iam-instance-profile"] = "juju-machine"
+	params.ModelConfig["controller-iam-instance-profile"] = "juju-controller"
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(profiles, jc.DeepEquals, []string{"juju-controller"})
+
+	testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	c.Assert(profiles, jc.DeepEquals, []string{"juju-controller", "juju-machine"})
+}
+
+func (t *localServerSuite) TestStartInstancePlacementGroup(c *gc.C) {
+	var groups []string
+	t.PatchValue(ec2.RunInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances) (*amzec2.RunInstancesResp, error) {
+		groups = append(groups, ri.PlacementGroupName)
+		return e.RunInstances(ri)
+	})
+
+	params := t.PrepareParams(c)
+	params.ModelConfig["placement-group"] = "hpc-cluster"
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	c.Assert(groups, jc.DeepEquals, []string{"hpc-cluster", "hpc-cluster"})
+}
+
+func (t *localServerSuite) TestStartInstancePlacementGroupError(c *gc.C) {
+	t.PatchValue(ec2.RunInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances) (*amzec2.RunInstancesResp, error) {
+		return nil, &amzec2.Error{
+			Code:    "InvalidPlacementGroup.Unknown",
+			Message: "The Placement Group 'hpc-cluster' is unknown.",
+		}
+	})
+
+	params := t.PrepareParams(c)
+	params.ModelConfig["placement-group"] = "hpc-cluster"
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, gc.ErrorMatches, `.*cannot run instances in placement group "hpc-cluster": The Placement Group 'hpc-cluster' is unknown.*`)
+}
+
+func (t *localServerSuite) TestBootstrapDryRun(c *gc.C) {
+	t.PatchValue(ec2.RunInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances) (*amzec2.RunInstancesResp, error) {
+		c.Fatalf("unexpected RunInstances call")
+		return nil, nil
+	})
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+		DryRun:           true,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, err = env.ControllerInstances(t.ControllerUUID)
+	c.Assert(err, gc.Equals, environs.ErrNotBootstrapped)
+}
+
+func (t *localServerSuite) TestBootstrapDryRunNoMatchingInstanceType(c *gc.C) {
+	env := t.Prepare(c)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig:     coretesting.FakeControllerConfig(),
+		AdminSecret:          testing.AdminSecret,
+		CAPrivateKey:         coretesting.CAKey,
+		BootstrapConstraints: constraints.MustParse("mem=1000T"),
+		DryRun:               true,
+	})
+	c.Assert(err, gc.ErrorMatches, "dry-run bootstrap failed: .*no instance types .*")
+
+	_, err = env.ControllerInstances(t.ControllerUUID)
+	c.Assert(err, gc.Equals, environs.ErrNotBootstrapped)
+}
+
+func (t *localServerSuite) TestStartInstanceCloudInitUserData(c *gc.C) {
+	params := t.PrepareParams(c)
+	params.ModelConfig["cloudinit-userdata"] = "runcmd:\n  - echo custom-runcmd\npackages:\n  - htop\n"
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	inst, _ := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+	ec2inst := t.srv.ec2srv.Instance(string(inst.Id()))
+	c.Assert(ec2inst, gc.NotNil)
+	userData, err := utils.Gunzip(ec2inst.UserData)
+	c.Assert(err, jc.ErrorIsNil)
+	var userDataMap map[interface{}]interface{}
+	err = goyaml.Unmarshal(userData, &userDataMap)
+	c.Assert(err, jc.ErrorIsNil)
+
+	// The user's entries are added to juju's own.
+	CheckPackage(c, userDataMap, "curl", true)
+	CheckPackage(c, userDataMap, "htop", true)
+	CheckScripts(c, userDataMap, "/var/lib/juju/agents/machine-1/agent.conf", true)
+	CheckScripts(c, userDataMap, "echo custom-runcmd", true)
+}
+
+func (t *localServerSuite) TestStartInstanceSpotRequestFailed(c *gc.C) {
+	t.PatchValue(ec2.RequestSpotInstances, func(e *amzec2.EC2, ri *amzec2.RunInstances, price string) (*amzec2.RunInstancesResp, error) {
+		return nil, errors.New(`spot request "sir-1" is cancelled`)
+	})
+
+	params := t.PrepareParams(c)
+	params.ModelConfig["spot-price"] = "0.05"
+	env := t.PrepareWithParams(c, params)
+	err := bootstrap.Bootstrap(envtesting.BootstrapContext(c), env, bootstrap.BootstrapParams{
+		ControllerConfig: coretesting.FakeControllerConfig(),
+		AdminSecret:      testing.AdminSecret,
+		CAPrivateKey:     coretesting.CAKey,
+	})
+	c.Assert(err, jc.ErrorIsNil)
+
+	_, _, _, err = testing.StartInstance(env, t.ControllerUUID, "1")
+	c.Assert(err, gc.ErrorMatches, `cannot run instances: spot request "sir-1" is cancelled`)
+}
+
 // addTestingSubnets adds a testing default VPC with 3 subnets in the EC2 test
 // server: 2 of the subnets are in the "test-available" AZ, the remaining - in
 // "test-unavailable". Returns a slice with the IDs of the created subnets.
@@ -1137,6 +1856,14 @@ func (t *localServerSuite) TestAddresses(c *gc.C) {
 		Value: "127.0.0.*",
 		Type:  network.IPv4Address,
 		Scope: network.ScopeCloudLocal,
+	}, {
+		Value: ".*\\.testing\\.invalid",
+		Type:  network.HostName,
+		Scope: network.ScopePublic,
+	}, {
+		Value: ".*\\.internal\\.invalid",
+		Type:  network.HostName,
+		Scope: network.ScopeCloudLocal,
 	}}
 	c.Assert(addrs, gc.HasLen, len(expected))
 	for i, addr := range addrs {
@@ -1192,6 +1919,28 @@ func (t *localServerSuite) TestPrecheckInstanceInvalidInstanceType(c *gc.C) {
 	c.Assert(err, gc.ErrorMatches, `invalid AWS instance type "m1.invalid" specified`)
 }
 
+func (t *localServerSuite) TestPrecheckInstanceInstanceTypeNotInRegion(c *gc.C) {
+	env := t.Prepare(c)
+	cons := constraints.MustParse("instance-type=m4.large")
+	placement := ""
+	err := env.PrecheckInstance(series.LatestLts(), cons, placement)
+	c.Assert(err, gc.ErrorMatches, `instance type "m4.large" not available in region "test" \(valid instance types: c1.medium, .*\)`)
+}
+
+func (t *localServerSuite) TestStartInstanceInstanceTypeNotInRegion(c *gc.C) {
+	env := t.prepareAndBootstrap(c)
+	cons := constraints.MustParse("instance-type=m4.large")
+	_, _, _, err := testing.StartInstanceWithConstraints(env, t.ControllerUUID, "1", cons)
+	c.Assert(err, gc.ErrorMatches, `instance type "m4.large" not available in region "test" \(valid instance types: .*m1.small.*\)`)
+}
+
+func (t *localServerSuite) TestStartInstanceInstanceType(c *gc.C) {
+	env := t.prepareAndBootstrap(c)
+	cons := constraints.MustParse("instance-type=m1.large")
+	inst, _ := testing.AssertStartInstanceWithConstraints(c, env, t.ControllerUUID, "1", cons)
+	c.Assert(ec2.InstanceEC2(inst).InstanceType, gc.Equals, "m1.large")
+}
+
 func (t *localServerSuite) TestPrecheckInstanceUnsupportedArch(c *gc.C) {
 	env := t.Prepare(c)
 	cons := constraints.MustParse("instance-type=cc1.4xlarge arch=i386")
@@ -1218,7 +1967,21 @@ func (t *localServerSuite) TestPrecheckInstanceAvailZoneUnknown(c *gc.C) {
 	env := t.Prepare(c)
 	placement := "zone=test-unknown"
 	err := env.PrecheckInstance(series.LatestLts(), constraints.Value{}, placement)
-	c.Assert(err, gc.ErrorMatches, `invalid availability zone "test-unknown"`)
+	c.Assert(err, gc.ErrorMatches, `invalid availability zone "test-unknown"; valid zones for region "test" are: test-available, test-impaired, test-unavailable`)
+}
+
+func (t *localServerSuite) TestPrecheckInstanceAdoptInstance(c *gc.C) {
+	env := t.Prepare(c)
+	placement := "instance-id=i-1234"
+	err := env.PrecheckInstance(series.LatestLts(), constraints.Value{}, placement)
+	c.Assert(err, gc.ErrorMatches, `cannot use placement "instance-id=i-1234": existing instances can only be adopted when bootstrapping`)
+}
+
+func (t *localServerSuite) TestPrecheckInstanceAdoptInvalidInstanceId(c *gc.C) {
+	env := t.Prepare(c)
+	placement := "instance-id=foo"
+	err := env.PrecheckInstance(series.LatestLts(), constraints.Value{}, placement)
+	c.Assert(err, gc.ErrorMatches, `instance id "foo" in placement "instance-id=foo" not valid`)
 }
 
 func (t *localServerSuite) TestValidateImageMetadata(c *gc.C) {
@@ -1411,6 +2174,32 @@ func (t *localServerSuite) TestInstanceTags(c *gc.C) {
 	})
 }
 
+func (t *localServerSuite) TestInstancesByTag(c *gc.C) {
+	env := t.prepareAndBootstrap(c)
+	controllerIds, err := env.ControllerInstances(t.ControllerUUID)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(controllerIds, gc.HasLen, 1)
+	inst1, _ := testing.AssertStartInstance(c, env, t.ControllerUUID, "1")
+
+	insts, err := ec2.InstancesByTag(env, map[string]string{
+		tags.JujuModel: coretesting.ModelTag.Id(),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	ids := make([]instance.Id, len(insts))
+	for i, inst := range insts {
+		ids[i] = inst.Id()
+	}
+	c.Assert(ids, jc.SameContents, []instance.Id{controllerIds[0], inst1.Id()})
+
+	insts, err = ec2.InstancesByTag(env, map[string]string{
+		tags.JujuModel:        coretesting.ModelTag.Id(),
+		tags.JujuIsController: "true",
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(insts, gc.HasLen, 1)
+	c.Assert(insts[0].Id(), gc.Equals, controllerIds[0])
+}
+
 func (t *localServerSuite) TestRootDiskTags(c *gc.C) {
 	env := t.prepareAndBootstrap(c)
 
@@ -1520,62 +2309,14 @@ func patchEC2ForTesting(c *gc.C) func() {
 	}
 }
 
-// If match is true, CheckScripts checks that at least one script started
-// by the cloudinit data matches the given regexp pattern, otherwise it
-// checks that no script matches.  It's exported so it can be used by tests
-// defined in ec2_test.
+// CheckScripts is kept for tests written against the ec2 package;
+// new tests should use cloudinittest.CheckScripts.
 func CheckScripts(c *gc.C, userDataMap map[interface{}]interface{}, pattern string, match bool) {
-	scripts0 := userDataMap["runcmd"]
-	if scripts0 == nil {
-		c.Errorf("cloudinit has no entry for runcmd")
-		return
-	}
-	scripts := scripts0.([]interface{})
-	re := regexp.MustCompile(pattern)
-	found := false
-	for _, s0 := range scripts {
-		s := s0.(string)
-		if re.MatchString(s) {
-			found = true
-		}
-	}
-	switch {
-	case match && !found:
-		c.Errorf("script %q not found in %q", pattern, scripts)
-	case !match && found:
-		c.Errorf("script %q found but not expected in %q", pattern, scripts)
-	}
+	cloudinittest.CheckScripts(c, userDataMap, pattern, match)
 }
 
-// CheckPackage checks that the cloudinit will or won't install the given
-// package, depending on the value of match.  It's exported so it can be
-// used by tests defined outside the ec2 package.
+// CheckPackage is kept for tests written against the ec2 package;
+// new tests should use cloudinittest.CheckPackage.
 func CheckPackage(c *gc.C, userDataMap map[interface{}]interface{}, pkg string, match bool) {
-	pkgs0 := userDataMap["packages"]
-	if pkgs0 == nil {
-		if match {
-			c.Errorf("cloudinit has no entry for packages")
-		}
-		return
-	}
-
-	pkgs := pkgs0.([]interface{})
-
-	found := false
-	for _, p0 := range pkgs {
-		p := p0.(string)
-		// p might be a space separate list of packages eg 'foo bar qed' so split them up
-		manyPkgs := set.NewStrings(strings.Split(p, " ")...)
-		hasPkg := manyPkgs.Contains(pkg)
-		if p == pkg || hasPkg {
-			found = true
-			break
-		}
-	}
-	switch {
-	case match && !found:
-		c.Errorf("package %q not found in %v", pkg, pkgs)
-	case !match && found:
-		c.Errorf("%q found but not expected in %v", pkg, pkgs)
-	}
+	cloudinittest.CheckPackage(c, userDataMap, pkg, match)
 }
@@ -5,6 +5,8 @@ package ec2
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/juju/errors"
 	"github.com/juju/loggo"
@@ -34,33 +36,39 @@ func (p environProvider) Open(args environs.OpenParams) (environs.Environ, error
 	e.cloud = args.Cloud
 	e.name = args.Config.Name()
 
-	var err error
-	e.ec2, e.s3, err = awsClients(args.Cloud)
-	if err != nil {
+	if err := e.SetConfig(args.Config); err != nil {
 		return nil, errors.Trace(err)
 	}
 
-	if err := e.SetConfig(args.Config); err != nil {
+	var err error
+	e.ec2, e.s3, err = awsClients(args.Cloud, e.ecfg())
+	if err != nil {
 		return nil, errors.Trace(err)
 	}
 	return e, nil
 }
 
-func awsClients(cloud environs.CloudSpec) (*ec2.EC2, *s3.S3, error) {
+// awsClients returns EC2 and S3 clients for the given cloud. The
+// region's endpoints are replaced by any set with the ec2-endpoint
+// and s3-endpoint attributes.
+func awsClients(cloud environs.CloudSpec, ecfg *environConfig) (*ec2.EC2, *s3.S3, error) {
 	if err := validateCloudSpec(cloud); err != nil {
 		return nil, nil, errors.Annotate(err, "validating cloud spec")
 	}
 
-	credentialAttrs := cloud.Credential.Attributes()
-	accessKey := credentialAttrs["access-key"]
-	secretKey := credentialAttrs["secret-key"]
-	auth := aws.Auth{
-		AccessKey: accessKey,
-		SecretKey: secretKey,
+	auth, err := resolveAuth(cloud.Credential.Attributes())
+	if err != nil {
+		return nil, nil, errors.Trace(err)
 	}
 
 	// TODO(axw) define region in terms of EC2 and S3 endpoints.
 	region := aws.Regions[cloud.Region]
+	if endpoint := ecfg.ec2Endpoint(); endpoint != "" {
+		region.EC2Endpoint = endpoint
+	}
+	if endpoint := ecfg.s3Endpoint(); endpoint != "" {
+		region.S3Endpoint = endpoint
+	}
 	signer := aws.SignV4Factory(region.Name, "ec2")
 	return ec2.New(auth, region, signer), s3.New(auth, region), nil
 }
@@ -86,7 +94,10 @@ func validateCloudSpec(c environs.CloudSpec) error {
 		return errors.Trace(err)
 	}
 	if _, ok := aws.Regions[c.Region]; !ok {
-		return errors.NotValidf("region name %q", c.Region)
+		return errors.NewNotValid(nil, fmt.Sprintf(
+			"region name %q not valid (valid regions: %s)",
+			c.Region, strings.Join(Regions(), ", "),
+		))
 	}
 	if c.Credential == nil {
 		return errors.NotValidf("missing credential")
@@ -94,9 +105,23 @@ func validateCloudSpec(c environs.CloudSpec) error {
 	if authType := c.Credential.AuthType(); authType != cloud.AccessKeyAuthType {
 		return errors.NotSupportedf("%q auth-type", authType)
 	}
+	if _, err := resolveAuth(c.Credential.Attributes()); err != nil {
+		return errors.Trace(err)
+	}
 	return nil
 }
 
+// Regions returns the sorted names of the EC2 regions known to the
+// provider.
+func Regions() []string {
+	names := make([]string, 0, len(allRegions))
+	for name := range allRegions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 // Validate is specified in the EnvironProvider interface.
 func (environProvider) Validate(cfg, old *config.Config) (valid *config.Config, err error) {
 	newEcfg, err := validateConfig(cfg, old)
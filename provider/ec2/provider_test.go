@@ -4,12 +4,20 @@
 package ec2_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
 	"github.com/juju/testing"
 	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	"github.com/juju/utils/set"
 	gc "gopkg.in/check.v1"
 
 	"github.com/juju/juju/cloud"
 	"github.com/juju/juju/environs"
+	"github.com/juju/juju/provider/ec2"
 	coretesting "github.com/juju/juju/testing"
 )
 
@@ -54,7 +62,13 @@ func (s *ProviderSuite) TestOpen(c *gc.C) {
 
 func (s *ProviderSuite) TestOpenInvalidRegion(c *gc.C) {
 	s.spec.Region = "foobar"
-	s.testOpenError(c, s.spec, `validating cloud spec: region name "foobar" not valid`)
+	s.testOpenError(c, s.spec, `validating cloud spec: region name "foobar" not valid \(valid regions: .*us-east-1.*\)`)
+}
+
+func (s *ProviderSuite) TestRegions(c *gc.C) {
+	regions := ec2.Regions()
+	c.Assert(set.NewStrings(regions...).Contains("us-east-1"), jc.IsTrue)
+	c.Assert(sort.StringsAreSorted(regions), jc.IsTrue)
 }
 
 func (s *ProviderSuite) TestOpenMissingCredential(c *gc.C) {
@@ -68,6 +82,88 @@ func (s *ProviderSuite) TestOpenUnsupportedCredential(c *gc.C) {
 	s.testOpenError(c, s.spec, `validating cloud spec: "userpass" auth-type not supported`)
 }
 
+func (s *ProviderSuite) TestOpenNoKeysOrProfile(c *gc.C) {
+	credential := cloud.NewCredential(cloud.AccessKeyAuthType, map[string]string{})
+	s.spec.Credential = &credential
+	s.testOpenError(c, s.spec, `validating cloud spec: credential with no access-key, secret-key or aws-profile not valid`)
+}
+
+func (s *ProviderSuite) TestOpenPartialKeys(c *gc.C) {
+	credential := cloud.NewCredential(cloud.AccessKeyAuthType, map[string]string{
+		"access-key": "foo",
+	})
+	s.spec.Credential = &credential
+	s.testOpenError(c, s.spec, `validating cloud spec: credential with only one of access-key and secret-key not valid`)
+}
+
+func (s *ProviderSuite) setUpSharedCredentials(c *gc.C) {
+	home := utils.Home()
+	dir := c.MkDir()
+	err := utils.SetHome(dir)
+	c.Assert(err, jc.ErrorIsNil)
+	s.AddCleanup(func(*gc.C) {
+		err := utils.SetHome(home)
+		c.Assert(err, jc.ErrorIsNil)
+	})
+	s.PatchEnvironment("USERPROFILE", dir)
+
+	location := filepath.Join(dir, ".aws")
+	err = os.MkdirAll(location, 0700)
+	c.Assert(err, jc.ErrorIsNil)
+	credData := `
+[fred]
+aws_access_key_id=aws-key-id
+aws_secret_access_key=aws-secret-access-key
+`[1:]
+	err = ioutil.WriteFile(filepath.Join(location, "credentials"), []byte(credData), 0600)
+	c.Assert(err, jc.ErrorIsNil)
+}
+
+func (s *ProviderSuite) TestOpenProfile(c *gc.C) {
+	s.setUpSharedCredentials(c)
+	credential := cloud.NewCredential(cloud.AccessKeyAuthType, map[string]string{
+		"aws-profile": "fred",
+	})
+	s.spec.Credential = &credential
+
+	env, err := s.provider.Open(environs.OpenParams{
+		Cloud:  s.spec,
+		Config: coretesting.ModelConfig(c),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	auth := ec2.EnvironEC2(env).Auth
+	c.Assert(auth.AccessKey, gc.Equals, "aws-key-id")
+	c.Assert(auth.SecretKey, gc.Equals, "aws-secret-access-key")
+}
+
+func (s *ProviderSuite) TestOpenProfileEnvironmentPrecedence(c *gc.C) {
+	s.setUpSharedCredentials(c)
+	s.PatchEnvironment("AWS_ACCESS_KEY_ID", "key-id")
+	s.PatchEnvironment("AWS_SECRET_ACCESS_KEY", "secret-access-key")
+	credential := cloud.NewCredential(cloud.AccessKeyAuthType, map[string]string{
+		"aws-profile": "fred",
+	})
+	s.spec.Credential = &credential
+
+	env, err := s.provider.Open(environs.OpenParams{
+		Cloud:  s.spec,
+		Config: coretesting.ModelConfig(c),
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	auth := ec2.EnvironEC2(env).Auth
+	c.Assert(auth.AccessKey, gc.Equals, "key-id")
+	c.Assert(auth.SecretKey, gc.Equals, "secret-access-key")
+}
+
+func (s *ProviderSuite) TestOpenProfileNotFound(c *gc.C) {
+	s.setUpSharedCredentials(c)
+	credential := cloud.NewCredential(cloud.AccessKeyAuthType, map[string]string{
+		"aws-profile": "wilma",
+	})
+	s.spec.Credential = &credential
+	s.testOpenError(c, s.spec, `validating cloud spec: reading AWS profile "wilma": profile in .* not found`)
+}
+
 func (s *ProviderSuite) testOpenError(c *gc.C, spec environs.CloudSpec, expect string) {
 	_, err := s.provider.Open(environs.OpenParams{
 		Cloud:  spec,
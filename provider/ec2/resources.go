@@ -0,0 +1,109 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+	"gopkg.in/amz.v3/ec2"
+)
+
+// ResourceType identifies a kind of EC2 resource created by juju.
+type ResourceType string
+
+const (
+	ResourceInstance      ResourceType = "instance"
+	ResourceSecurityGroup ResourceType = "security-group"
+	ResourceVolume        ResourceType = "volume"
+)
+
+// Resource identifies an EC2 resource tagged as belonging to a model.
+type Resource struct {
+	Type ResourceType
+	Id   string
+}
+
+// ListManagedResources returns the instances, security groups and EBS
+// volumes tagged with the environ's model UUID. Terminated instances
+// and instance root disks are not included.
+func (e *environ) ListManagedResources() ([]Resource, error) {
+	var resources []Resource
+
+	filter := ec2.NewFilter()
+	filter.Add("instance-state-name", aliveInstanceStates...)
+	e.addModelFilter(filter)
+	instIds, err := e.allInstanceIDs(filter)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, id := range instIds {
+		resources = append(resources, Resource{ResourceInstance, string(id)})
+	}
+
+	groups, err := e.modelSecurityGroups()
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	for _, g := range groups {
+		resources = append(resources, Resource{ResourceSecurityGroup, g.Id})
+	}
+
+	filter = ec2.NewFilter()
+	e.addModelFilter(filter)
+	volIds, err := listVolumes(e.ec2, filter)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing volumes")
+	}
+	for _, id := range volIds {
+		resources = append(resources, Resource{ResourceVolume, id})
+	}
+	return resources, nil
+}
+
+// modelSecurityGroups returns the details of all security groups tagged
+// with the environ's model UUID.
+func (e *environ) modelSecurityGroups() ([]ec2.SecurityGroup, error) {
+	filter := ec2.NewFilter()
+	e.addModelFilter(filter)
+	resp, err := e.ec2.SecurityGroups(nil, filter)
+	if err != nil {
+		return nil, errors.Annotate(err, "listing security groups")
+	}
+	groups := make([]ec2.SecurityGroup, len(resp.Groups))
+	for i, info := range resp.Groups {
+		groups[i] = ec2.SecurityGroup{Id: info.Id, Name: info.Name}
+	}
+	return groups, nil
+}
+
+// destroyLeakedResources deletes the volumes and security groups that
+// are still tagged with the environ's model UUID once its instances
+// have been terminated.
+func (e *environ) destroyLeakedResources() error {
+	resources, err := e.ListManagedResources()
+	if err != nil {
+		return errors.Trace(err)
+	}
+	var volIds []string
+	var groups []ec2.SecurityGroup
+	for _, r := range resources {
+		switch r.Type {
+		case ResourceVolume:
+			volIds = append(volIds, r.Id)
+		case ResourceSecurityGroup:
+			groups = append(groups, ec2.SecurityGroup{Id: r.Id})
+		}
+	}
+	for i, err := range destroyVolumes(e.ec2, volIds) {
+		if err != nil {
+			return errors.Annotatef(err, "destroying volume %q", volIds[i])
+		}
+	}
+	for _, g := range groups {
+		if err := deleteSecurityGroupInsistently(e.ec2, g, clock.WallClock); err != nil {
+			return errors.Annotatef(err, "cannot delete security group %q", g.Id)
+		}
+	}
+	return nil
+}
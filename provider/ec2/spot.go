@@ -0,0 +1,168 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"sort"
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"gopkg.in/amz.v3/ec2"
+)
+
+const (
+	spotRequestActive    = "active"
+	spotRequestCancelled = "cancelled"
+	spotRequestFailed    = "failed"
+	spotRequestClosed    = "closed"
+)
+
+// spotRequestAttempt is the attempt strategy used when waiting
+// for a spot instance request to be fulfilled.
+//
+// TODO(katco): 2016-08-09: lp:1611427
+var spotRequestAttempt = utils.AttemptStrategy{
+	Total: 5 * time.Minute,
+	Delay: 5 * time.Second,
+}
+
+var requestSpotInstances = _requestSpotInstances
+
+// requestSpotInstances bids for a spot instance at the given price,
+// using the launch parameters from ri, and waits for the request to
+// be fulfilled. The resulting instance is returned as if it had been
+// started with RunInstances. If the request is cancelled, fails or is
+// not fulfilled in time, an error is returned and any outstanding
+// request is cancelled.
+func _requestSpotInstances(e *ec2.EC2, ri *ec2.RunInstances, price string) (*ec2.RunInstancesResp, error) {
+	resp, err := e.RequestSpotInstances(spotInstancesRequest(ri, price))
+	if err != nil {
+		return nil, errors.Annotate(err, "requesting spot instance")
+	}
+	if len(resp.SpotRequestResults) != 1 {
+		return nil, errors.Errorf("expected 1 spot request, got %d", len(resp.SpotRequestResults))
+	}
+	requestId := resp.SpotRequestResults[0].SpotRequestId
+
+	instanceId, err := waitSpotRequestFulfilled(e, requestId)
+	if err != nil {
+		if _, cancelErr := e.CancelSpotRequests([]string{requestId}); cancelErr != nil {
+			logger.Errorf("cannot cancel spot request %q: %v", requestId, cancelErr)
+		}
+		return nil, errors.Trace(err)
+	}
+	logger.Infof("spot request %q fulfilled by instance %q", requestId, instanceId)
+
+	instResp, err := e.Instances([]string{instanceId}, nil)
+	if err != nil {
+		return nil, errors.Annotatef(err, "fetching spot instance %q", instanceId)
+	}
+	var insts []ec2.Instance
+	for _, r := range instResp.Reservations {
+		insts = append(insts, r.Instances...)
+	}
+	return &ec2.RunInstancesResp{Instances: insts}, nil
+}
+
+// spotInstancesRequest returns the request for a single spot instance
+// at the given price, launched with the parameters from ri.
+func spotInstancesRequest(ri *ec2.RunInstances, price string) *ec2.RequestSpotInstances {
+	return &ec2.RequestSpotInstances{
+		SpotPrice:           price,
+		InstanceCount:       1,
+		ImageId:             ri.ImageId,
+		InstanceType:        ri.InstanceType,
+		SecurityGroups:      ri.SecurityGroups,
+		UserData:            ri.UserData,
+		AvailZone:           ri.AvailZone,
+		SubnetId:            ri.SubnetId,
+		BlockDeviceMappings: ri.BlockDeviceMappings,
+		IAMInstanceProfile:  ri.IAMInstanceProfile,
+		PlacementGroupName:  ri.PlacementGroupName,
+	}
+}
+
+// waitSpotRequestFulfilled polls the spot request with the given ID
+// until it is active and has an instance associated with it, returning
+// the instance ID.
+func waitSpotRequestFulfilled(e *ec2.EC2, requestId string) (string, error) {
+	var state string
+	for a := spotRequestAttempt.Start(); a.Next(); {
+		resp, err := e.DescribeSpotRequests([]string{requestId}, nil)
+		if err != nil {
+			if isNotFoundError(err) || ec2ErrCode(err) == "InvalidSpotInstanceRequestID.NotFound" {
+				// The request may not be visible yet due to
+				// eventual consistency.
+				continue
+			}
+			return "", errors.Annotatef(err, "fetching spot request %q", requestId)
+		}
+		if len(resp.SpotRequestResults) != 1 {
+			continue
+		}
+		result := resp.SpotRequestResults[0]
+		state = result.State
+		switch state {
+		case spotRequestActive:
+			if result.InstanceId != "" {
+				return result.InstanceId, nil
+			}
+		case spotRequestCancelled, spotRequestFailed, spotRequestClosed:
+			return "", errors.Errorf("spot request %q is %s", requestId, state)
+		}
+	}
+	return "", errors.Errorf("timed out waiting for spot request %q to be fulfilled (state %q)", requestId, state)
+}
+
+// SpotPrice records the spot price of an instance type in an
+// availability zone from a point in time.
+type SpotPrice struct {
+	InstanceType       string
+	AvailabilityZone   string
+	ProductDescription string
+
+	// Price is the hourly price in US dollars, as reported by EC2
+	// and as accepted by the spot-price attribute.
+	Price string
+
+	Timestamp time.Time
+}
+
+var describeSpotPriceHistory = func(e *ec2.EC2, req *ec2.DescribeSpotPriceHistory) (*ec2.DescribeSpotPriceHistoryResp, error) {
+	return e.DescribeSpotPriceHistory(req)
+}
+
+// SpotPriceHistory returns the spot prices of the given instance type
+// in the given availability zone since the given time, oldest first.
+// If az is empty, prices for all of the region's zones are returned.
+// It can be used whether or not spot-price is set.
+func (e *environ) SpotPriceHistory(instanceType, az string, since time.Time) ([]SpotPrice, error) {
+	resp, err := describeSpotPriceHistory(e.ec2, &ec2.DescribeSpotPriceHistory{
+		InstanceType:     []string{instanceType},
+		AvailabilityZone: az,
+		StartTime:        since,
+	})
+	if err != nil {
+		return nil, errors.Annotatef(err, "fetching spot price history for %q", instanceType)
+	}
+	prices := make([]SpotPrice, len(resp.History))
+	for i, h := range resp.History {
+		prices[i] = SpotPrice{
+			InstanceType:       h.InstanceType,
+			AvailabilityZone:   h.AvailabilityZone,
+			ProductDescription: h.ProductDescription,
+			Price:              h.SpotPrice,
+			Timestamp:          h.Timestamp,
+		}
+	}
+	sort.Sort(byTimestamp(prices))
+	return prices, nil
+}
+
+type byTimestamp []SpotPrice
+
+func (p byTimestamp) Len() int           { return len(p) }
+func (p byTimestamp) Less(i, j int) bool { return p[i].Timestamp.Before(p[j].Timestamp) }
+func (p byTimestamp) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
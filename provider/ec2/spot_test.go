@@ -0,0 +1,45 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	jc "github.com/juju/testing/checkers"
+	amzec2 "gopkg.in/amz.v3/ec2"
+	gc "gopkg.in/check.v1"
+)
+
+type spotSuite struct{}
+
+var _ = gc.Suite(&spotSuite{})
+
+func (*spotSuite) TestSpotInstancesRequest(c *gc.C) {
+	groups := []amzec2.SecurityGroup{{Id: "sg-1", Name: "juju-group"}}
+	disks := []amzec2.BlockDeviceMapping{{DeviceName: "/dev/sda1", VolumeSize: 8}}
+	ri := &amzec2.RunInstances{
+		MinCount:            1,
+		MaxCount:            1,
+		ImageId:             "ami-a7f539ce",
+		InstanceType:        "m3.medium",
+		SecurityGroups:      groups,
+		UserData:            []byte("#cloud-config"),
+		AvailZone:           "us-east-1a",
+		SubnetId:            "subnet-1",
+		BlockDeviceMappings: disks,
+		IAMInstanceProfile:  "juju-machine",
+		PlacementGroupName:  "hpc-cluster",
+	}
+	c.Assert(spotInstancesRequest(ri, "0.05"), jc.DeepEquals, &amzec2.RequestSpotInstances{
+		SpotPrice:           "0.05",
+		InstanceCount:       1,
+		ImageId:             "ami-a7f539ce",
+		InstanceType:        "m3.medium",
+		SecurityGroups:      groups,
+		UserData:            []byte("#cloud-config"),
+		AvailZone:           "us-east-1a",
+		SubnetId:            "subnet-1",
+		BlockDeviceMappings: disks,
+		IAMInstanceProfile:  "juju-machine",
+		PlacementGroupName:  "hpc-cluster",
+	})
+}
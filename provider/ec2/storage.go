@@ -4,9 +4,11 @@
 package ec2
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"net"
+	"os"
 	"sync"
 	"time"
 
@@ -57,22 +59,185 @@ func (s *ec2storage) makeBucket() error {
 	return nil
 }
 
+// MultipartThreshold is the size above which files are uploaded to
+// the control bucket in parts, so that a part which fails to upload
+// can be retried without starting the whole upload again.
+var MultipartThreshold int64 = 64 * 1024 * 1024
+
+// MultipartPartSize is the size of each part of a multipart upload,
+// except the last. S3 requires parts other than the last to be at
+// least 5MiB.
+var MultipartPartSize int64 = 16 * 1024 * 1024
+
+// MultipartRetries is the number of times the upload of each part of
+// a multipart upload is retried while S3 throttles it, before the
+// upload is abandoned.
+var MultipartRetries = 3
+
+// ThrottleMaxAttempts is the number of times a file is uploaded to or
+// downloaded from the control bucket while S3 responds with throttling
+// or other transient server errors.
+var ThrottleMaxAttempts = 5
+
+// throttleRetryDelay is how long to wait before the first retry of a
+// throttled request. The delay doubles with each further retry.
+var throttleRetryDelay = 200 * time.Millisecond
+
 func (s *ec2storage) Put(file string, r io.Reader, length int64) error {
 	if err := s.makeBucket(); err != nil {
 		return fmt.Errorf("cannot make S3 control bucket: %v", err)
 	}
-	err := s.bucket.PutReader(file, r, length, "binary/octet-stream", s3.Private)
+	var err error
+	if length > MultipartThreshold {
+		err = s.putMultipart(file, r, length)
+	} else {
+		err = s.putReader(file, r, length)
+	}
 	if err != nil {
 		return fmt.Errorf("cannot write file %q to control bucket: %v", file, err)
 	}
 	return nil
 }
 
+// putReader uploads r in a single request. If S3 throttles the request
+// and r can be rewound, the upload is retried.
+func (s *ec2storage) putReader(file string, r io.Reader, length int64) error {
+	put := func() error {
+		return s.bucket.PutReader(file, r, length, "binary/octet-stream", s3.Private)
+	}
+	seeker, ok := r.(io.Seeker)
+	if !ok {
+		return put()
+	}
+	start, err := seeker.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return put()
+	}
+	first := true
+	return retryThrottled(ThrottleMaxAttempts, func() error {
+		if !first {
+			if _, err := seeker.Seek(start, os.SEEK_SET); err != nil {
+				return errors.Annotate(err, "rewinding file")
+			}
+		}
+		first = false
+		return put()
+	})
+}
+
+func (s *ec2storage) putMultipart(file string, r io.Reader, length int64) error {
+	var multi *s3.Multi
+	err := retryThrottled(ThrottleMaxAttempts, func() error {
+		var err error
+		multi, err = s.bucket.InitMulti(file, "binary/octet-stream", s3.Private)
+		return err
+	})
+	if err != nil {
+		return errors.Annotate(err, "starting multipart upload")
+	}
+	if err := putParts(multi, r, length, MultipartPartSize, MultipartRetries); err != nil {
+		if abortErr := multi.Abort(); abortErr != nil {
+			logger.Errorf("cannot abort multipart upload of %q: %v", file, abortErr)
+		}
+		return errors.Trace(err)
+	}
+	return nil
+}
+
+// multipartUpload is the part of *s3.Multi used by putParts.
+type multipartUpload interface {
+	PutPart(n int, r io.ReadSeeker) (s3.Part, error)
+	Complete(parts []s3.Part) error
+}
+
+// putParts reads length bytes from r in parts of partSize bytes,
+// uploading each and retrying it up to retries times while S3 throttles
+// it, and completes the upload once they have all been read. Only one
+// part is held in memory at a time. If r holds fewer than length bytes,
+// the upload is not completed. Completing the upload is retried while
+// S3 throttles it, up to ThrottleMaxAttempts times.
+func putParts(upload multipartUpload, r io.Reader, length, partSize int64, retries int) error {
+	var parts []s3.Part
+	var total int64
+	r = io.LimitReader(r, length)
+	buf := make([]byte, partSize)
+	for n := 1; ; n++ {
+		size, err := io.ReadFull(r, buf)
+		if err == io.EOF && n > 1 {
+			break
+		}
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return errors.Annotate(err, "reading file")
+		}
+		part, err := putPart(upload, n, buf[:size], retries)
+		if err != nil {
+			return errors.Trace(err)
+		}
+		parts = append(parts, part)
+		total += int64(size)
+		if size < len(buf) {
+			break
+		}
+	}
+	if total != length {
+		return errors.Errorf("read %d bytes, expected %d", total, length)
+	}
+	err := retryThrottled(ThrottleMaxAttempts, func() error {
+		return upload.Complete(parts)
+	})
+	if err != nil {
+		return errors.Annotate(err, "completing multipart upload")
+	}
+	return nil
+}
+
+func putPart(upload multipartUpload, n int, data []byte, retries int) (s3.Part, error) {
+	var part s3.Part
+	err := retryThrottled(retries+1, func() error {
+		var err error
+		part, err = upload.PutPart(n, bytes.NewReader(data))
+		return err
+	})
+	if err != nil {
+		return s3.Part{}, errors.Annotatef(err, "uploading part %d", n)
+	}
+	return part, nil
+}
+
 func (s *ec2storage) Get(file string) (r io.ReadCloser, err error) {
-	r, err = s.bucket.GetReader(file)
+	err = retryThrottled(ThrottleMaxAttempts, func() error {
+		r, err = s.bucket.GetReader(file)
+		return err
+	})
 	return r, maybeNotFound(err)
 }
 
+// retryThrottled calls f until it succeeds, returns an error other
+// than a throttling or transient server error, or has been called
+// maxAttempts times. The delay between calls grows exponentially.
+func retryThrottled(maxAttempts int, f func() error) error {
+	delay := throttleRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= maxAttempts || !isThrottleError(err) {
+			return err
+		}
+		logger.Debugf("S3 request throttled (attempt %d): %v", attempt, err)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// isThrottleError reports whether err is an S3 error asking the client
+// to slow down, or a transient 5xx server error.
+func isThrottleError(err error) bool {
+	switch s3ErrCode(err) {
+	case "SlowDown", "RequestTimeout":
+		return true
+	}
+	return s3ErrorStatusCode(err) >= 500
+}
+
 func (s *ec2storage) URL(name string) (string, error) {
 	const sevenDays = 168 * time.Hour
 	const maxExpiratoryPeriod = sevenDays
@@ -0,0 +1,203 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2_test
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/juju/errors"
+	jc "github.com/juju/testing/checkers"
+	"gopkg.in/amz.v3/s3"
+	gc "gopkg.in/check.v1"
+
+	"github.com/juju/juju/provider/ec2"
+	coretesting "github.com/juju/juju/testing"
+)
+
+type multipartSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&multipartSuite{})
+
+func (s *multipartSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(ec2.ThrottleRetryDelay, time.Duration(0))
+}
+
+func (*multipartSuite) TestPutParts(c *gc.C) {
+	upload := &fakeMultipartUpload{}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefghij"), 10, 4, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(upload.data, jc.DeepEquals, []string{"abcd", "efgh", "ij"})
+	c.Assert(upload.completed, jc.DeepEquals, []s3.Part{
+		{N: 1, ETag: "abcd", Size: 4},
+		{N: 2, ETag: "efgh", Size: 4},
+		{N: 3, ETag: "ij", Size: 2},
+	})
+}
+
+func (*multipartSuite) TestPutPartsExactMultiple(c *gc.C) {
+	upload := &fakeMultipartUpload{}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefgh"), 8, 4, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(upload.data, jc.DeepEquals, []string{"abcd", "efgh"})
+	c.Assert(upload.completed, gc.HasLen, 2)
+}
+
+func (*multipartSuite) TestPutPartsReadsLength(c *gc.C) {
+	upload := &fakeMultipartUpload{}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefghij"), 6, 4, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(upload.data, jc.DeepEquals, []string{"abcd", "ef"})
+	c.Assert(upload.completed, gc.HasLen, 2)
+}
+
+func (*multipartSuite) TestPutPartsShortRead(c *gc.C) {
+	upload := &fakeMultipartUpload{}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefghij"), 12, 4, 0)
+	c.Assert(err, gc.ErrorMatches, "read 10 bytes, expected 12")
+	c.Assert(upload.completed, gc.IsNil)
+}
+
+func (*multipartSuite) TestPutPartsRetriesPart(c *gc.C) {
+	upload := &fakeMultipartUpload{
+		errors: []error{nil, slowDownError, slowDownError},
+	}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefghij"), 10, 4, 2)
+	c.Assert(err, jc.ErrorIsNil)
+	// Only the failed part is uploaded again.
+	c.Assert(upload.data, jc.DeepEquals, []string{"abcd", "efgh", "efgh", "efgh", "ij"})
+	c.Assert(upload.completed, gc.HasLen, 3)
+}
+
+func (*multipartSuite) TestPutPartsRetriesExhausted(c *gc.C) {
+	upload := &fakeMultipartUpload{
+		errors: []error{nil, slowDownError, slowDownError},
+	}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefghij"), 10, 4, 1)
+	c.Assert(err, gc.ErrorMatches, "uploading part 2: please reduce your request rate")
+	c.Assert(upload.completed, gc.IsNil)
+}
+
+func (*multipartSuite) TestPutPartsNotRetryable(c *gc.C) {
+	upload := &fakeMultipartUpload{
+		errors: []error{nil, errors.New("access denied")},
+	}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefghij"), 10, 4, 2)
+	c.Assert(err, gc.ErrorMatches, "uploading part 2: access denied")
+	c.Assert(upload.data, jc.DeepEquals, []string{"abcd", "efgh"})
+	c.Assert(upload.completed, gc.IsNil)
+}
+
+func (*multipartSuite) TestPutPartsRetriesComplete(c *gc.C) {
+	upload := &fakeMultipartUpload{
+		completeErrors: []error{slowDownError, slowDownError},
+	}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefghij"), 10, 4, 0)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(upload.completeCalls, gc.Equals, 3)
+	c.Assert(upload.completed, gc.HasLen, 3)
+}
+
+func (s *multipartSuite) TestPutPartsCompleteRetriesExhausted(c *gc.C) {
+	s.PatchValue(&ec2.ThrottleMaxAttempts, 2)
+	upload := &fakeMultipartUpload{
+		completeErrors: []error{slowDownError, slowDownError},
+	}
+	err := ec2.PutParts(upload, strings.NewReader("abcdefghij"), 10, 4, 0)
+	c.Assert(err, gc.ErrorMatches, "completing multipart upload: please reduce your request rate")
+	c.Assert(upload.completeCalls, gc.Equals, 2)
+	c.Assert(upload.completed, gc.IsNil)
+}
+
+var slowDownError = &s3.Error{StatusCode: 503, Code: "SlowDown", Message: "please reduce your request rate"}
+
+type fakeMultipartUpload struct {
+	errors         []error
+	data           []string
+	completeErrors []error
+	completeCalls  int
+	completed      []s3.Part
+}
+
+func (u *fakeMultipartUpload) PutPart(n int, r io.ReadSeeker) (s3.Part, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return s3.Part{}, err
+	}
+	u.data = append(u.data, string(data))
+	if len(u.errors) > 0 {
+		err, u.errors = u.errors[0], u.errors[1:]
+		if err != nil {
+			return s3.Part{}, err
+		}
+	}
+	return s3.Part{N: n, ETag: string(data), Size: int64(len(data))}, nil
+}
+
+func (u *fakeMultipartUpload) Complete(parts []s3.Part) error {
+	u.completeCalls++
+	if len(u.completeErrors) > 0 {
+		var err error
+		err, u.completeErrors = u.completeErrors[0], u.completeErrors[1:]
+		if err != nil {
+			return err
+		}
+	}
+	u.completed = parts
+	return nil
+}
+
+type throttleSuite struct {
+	coretesting.BaseSuite
+}
+
+var _ = gc.Suite(&throttleSuite{})
+
+func (s *throttleSuite) SetUpTest(c *gc.C) {
+	s.BaseSuite.SetUpTest(c)
+	s.PatchValue(ec2.ThrottleRetryDelay, time.Duration(0))
+}
+
+func (*throttleSuite) TestRetryThrottled(c *gc.C) {
+	errs := []error{
+		&s3.Error{StatusCode: 503, Code: "SlowDown"},
+		&s3.Error{StatusCode: 500, Code: "InternalError"},
+		nil,
+	}
+	calls := 0
+	err := ec2.RetryThrottled(5, func() error {
+		err := errs[calls]
+		calls++
+		return err
+	})
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(calls, gc.Equals, 3)
+}
+
+func (*throttleSuite) TestRetryThrottledAttemptsExhausted(c *gc.C) {
+	calls := 0
+	err := ec2.RetryThrottled(3, func() error {
+		calls++
+		return &s3.Error{StatusCode: 503, Code: "SlowDown", Message: "please reduce your request rate"}
+	})
+	c.Assert(err, gc.ErrorMatches, "please reduce your request rate")
+	c.Assert(calls, gc.Equals, 3)
+}
+
+func (*throttleSuite) TestRetryThrottledNotRetryable(c *gc.C) {
+	for _, code := range []int{403, 404} {
+		calls := 0
+		err := ec2.RetryThrottled(5, func() error {
+			calls++
+			return &s3.Error{StatusCode: code, Message: "nope"}
+		})
+		c.Check(err, gc.ErrorMatches, "nope")
+		c.Check(calls, gc.Equals, 1)
+	}
+}
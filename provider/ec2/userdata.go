@@ -5,23 +5,103 @@
 package ec2
 
 import (
+	"encoding/base64"
+
 	"github.com/juju/errors"
 	"github.com/juju/utils"
 	jujuos "github.com/juju/utils/os"
+	"gopkg.in/yaml.v2"
 
 	"github.com/juju/juju/cloudconfig/cloudinit"
 	"github.com/juju/juju/cloudconfig/providerinit/renderers"
 )
 
-type AmazonRenderer struct{}
+type AmazonRenderer struct {
+	// UserData holds a YAML cloud-config fragment, supplied with the
+	// cloudinit-userdata config attribute, that is merged into the
+	// rendered cloud-config on Ubuntu and CentOS.
+	UserData string
+}
 
-func (AmazonRenderer) Render(cfg cloudinit.CloudConfig, os jujuos.OSType) ([]byte, error) {
+func (r AmazonRenderer) Render(cfg cloudinit.CloudConfig, os jujuos.OSType) ([]byte, error) {
 	switch os {
 	case jujuos.Ubuntu, jujuos.CentOS:
-		return renderers.RenderYAML(cfg, utils.Gzip)
+		if r.UserData == "" {
+			return renderers.RenderYAML(cfg, utils.Gzip)
+		}
+		out, err := renderers.RenderYAML(cfg)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		out, err = mergeCloudInitUserData(out, r.UserData)
+		if err != nil {
+			return nil, errors.Trace(err)
+		}
+		return utils.Gzip(out), nil
 	case jujuos.Windows:
 		return renderers.RenderYAML(cfg, renderers.WinEmbedInScript, renderers.AddPowershellTags)
 	default:
 		return nil, errors.Errorf("Cannot encode userdata for OS: %s", os.String())
 	}
 }
+
+// maxUserDataSize is the largest user data, once base64-encoded for the
+// RunInstances request, that EC2 accepts.
+const maxUserDataSize = 16 * 1024
+
+// checkUserDataSize returns an error if EC2 would reject the given user
+// data as too large. Ubuntu and CentOS user data has already been
+// compressed by AmazonRenderer, so there is nothing more to be done if
+// it is still too large.
+func checkUserDataSize(userData []byte) error {
+	if size := base64.StdEncoding.EncodedLen(len(userData)); size > maxUserDataSize {
+		return errors.Errorf("user data exceeds EC2 16KB limit (%d bytes)", size)
+	}
+	return nil
+}
+
+// parseCloudInitUserData parses the given YAML cloud-config fragment.
+func parseCloudInitUserData(userData string) (map[string]interface{}, error) {
+	var attrs map[string]interface{}
+	if err := yaml.Unmarshal([]byte(userData), &attrs); err != nil {
+		return nil, errors.Trace(err)
+	}
+	return attrs, nil
+}
+
+// mergeCloudInitUserData merges the given YAML cloud-config fragment
+// into the rendered cloud-config. Where both hold a list for the same
+// key, such as runcmd, the fragment's entries are appended to the
+// rendered ones; any other conflicting key keeps its rendered value.
+func mergeCloudInitUserData(rendered []byte, userData string) ([]byte, error) {
+	var attrs map[string]interface{}
+	if err := yaml.Unmarshal(rendered, &attrs); err != nil {
+		return nil, errors.Annotate(err, "parsing rendered cloud-config")
+	}
+	extra, err := parseCloudInitUserData(userData)
+	if err != nil {
+		return nil, errors.Annotate(err, "parsing cloudinit-userdata")
+	}
+	if attrs == nil {
+		attrs = make(map[string]interface{})
+	}
+	for key, value := range extra {
+		existing, ok := attrs[key]
+		if !ok {
+			attrs[key] = value
+			continue
+		}
+		existingList, ok := existing.([]interface{})
+		valueList, ok2 := value.([]interface{})
+		if ok && ok2 {
+			attrs[key] = append(existingList, valueList...)
+			continue
+		}
+		logger.Warningf("ignoring cloudinit-userdata %q, which conflicts with juju's cloud-config", key)
+	}
+	data, err := yaml.Marshal(attrs)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return append([]byte("#cloud-config\n"), data...), nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/juju/utils"
 	"github.com/juju/utils/os"
 	gc "gopkg.in/check.v1"
+	goyaml "gopkg.in/yaml.v2"
 
 	"github.com/juju/juju/cloudconfig/cloudinit/cloudinittest"
 	"github.com/juju/juju/cloudconfig/providerinit/renderers"
@@ -35,6 +36,31 @@ func (s *UserdataSuite) TestAmazonUnix(c *gc.C) {
 	c.Assert(result, jc.DeepEquals, utils.Gzip(cloudcfg.YAML))
 }
 
+func (s *UserdataSuite) TestAmazonUnixUserData(c *gc.C) {
+	renderer := ec2.AmazonRenderer{
+		UserData: "runcmd:\n  - echo user\npackages: [htop]\napt_mirror: http://mirror.example.com/ubuntu\noutput: ignored\n",
+	}
+	cloudcfg := &cloudinittest.CloudConfig{
+		YAML: []byte("#cloud-config\nruncmd:\n- echo juju\noutput: juju\n"),
+	}
+
+	result, err := renderer.Render(cloudcfg, os.Ubuntu)
+	c.Assert(err, jc.ErrorIsNil)
+	data, err := utils.Gunzip(result)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(string(data), jc.HasPrefix, "#cloud-config\n")
+
+	var attrs map[string]interface{}
+	err = goyaml.Unmarshal(data, &attrs)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(attrs, jc.DeepEquals, map[string]interface{}{
+		"runcmd":     []interface{}{"echo juju", "echo user"},
+		"packages":   []interface{}{"htop"},
+		"apt_mirror": "http://mirror.example.com/ubuntu",
+		"output":     "juju",
+	})
+}
+
 func (s *UserdataSuite) TestAmazonWindows(c *gc.C) {
 	renderer := ec2.AmazonRenderer{}
 	cloudcfg := &cloudinittest.CloudConfig{YAML: []byte("yaml")}
@@ -54,3 +80,12 @@ func (s *UserdataSuite) TestAmazonUnknownOS(c *gc.C) {
 	c.Assert(result, gc.IsNil)
 	c.Assert(err, gc.ErrorMatches, "Cannot encode userdata for OS: GenericLinux")
 }
+
+func (s *UserdataSuite) TestCheckUserDataSize(c *gc.C) {
+	// 12KiB of user data is exactly 16KiB once base64-encoded.
+	err := ec2.CheckUserDataSize(make([]byte, 12*1024))
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = ec2.CheckUserDataSize(make([]byte, 12*1024+1))
+	c.Assert(err, gc.ErrorMatches, `user data exceeds EC2 16KB limit \(16388 bytes\)`)
+}
@@ -0,0 +1,151 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package ec2
+
+import (
+	"time"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/environs"
+	"github.com/juju/juju/instance"
+	"github.com/juju/juju/network"
+	"github.com/juju/juju/worker/catacomb"
+)
+
+// instanceStatusPollInterval is how often WatchInstanceStatus asks
+// EC2 for the state of the watched instances.
+var instanceStatusPollInterval = 2 * time.Second
+
+var _ environs.InstanceStatusWatcher = (*environ)(nil)
+
+// WatchInstanceStatus is specified in the environs.InstanceStatusWatcher
+// interface. EC2 has no push notification of instance state, so the
+// instances are polled; a result is only sent when the status or
+// addresses of an instance differ from those last sent.
+func (e *environ) WatchInstanceStatus(clk clock.Clock, ids []instance.Id) (environs.InstanceStatusWatch, error) {
+	w := &instanceStatusWatcher{
+		env:     e,
+		clock:   clk,
+		ids:     ids,
+		changes: make(chan environs.InstancesResult),
+	}
+	err := catacomb.Invoke(catacomb.Plan{
+		Site: &w.catacomb,
+		Work: w.loop,
+	})
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
+	return w, nil
+}
+
+// instanceStatusWatcher implements environs.InstanceStatusWatch by
+// polling the environ's instances.
+type instanceStatusWatcher struct {
+	catacomb catacomb.Catacomb
+	env      *environ
+	clock    clock.Clock
+	ids      []instance.Id
+	changes  chan environs.InstancesResult
+}
+
+// Changes is part of the environs.InstanceStatusWatch interface.
+func (w *instanceStatusWatcher) Changes() <-chan environs.InstancesResult {
+	return w.changes
+}
+
+// Kill is part of the worker.Worker interface.
+func (w *instanceStatusWatcher) Kill() {
+	w.catacomb.Kill(nil)
+}
+
+// Wait is part of the worker.Worker interface.
+func (w *instanceStatusWatcher) Wait() error {
+	return w.catacomb.Wait()
+}
+
+func (w *instanceStatusWatcher) loop() error {
+	defer close(w.changes)
+
+	var (
+		last    []instanceSnapshot
+		first   = true
+		changes chan<- environs.InstancesResult
+		result  environs.InstancesResult
+	)
+	poll := func() {
+		insts, err := w.env.Instances(w.ids)
+		current := snapshotInstances(insts)
+		if first || err != nil || !snapshotsEqual(current, last) {
+			// An unsent result is replaced by the latest one.
+			result = environs.InstancesResult{Instances: insts, Err: err}
+			changes = w.changes
+			first = false
+			last = current
+		}
+	}
+
+	poll()
+	next := w.clock.After(instanceStatusPollInterval)
+	for {
+		select {
+		case <-w.catacomb.Dying():
+			return w.catacomb.ErrDying()
+		case changes <- result:
+			changes = nil
+		case <-next:
+			poll()
+			next = w.clock.After(instanceStatusPollInterval)
+		}
+	}
+}
+
+// instanceSnapshot records the parts of an instance that
+// WatchInstanceStatus reports changes to.
+type instanceSnapshot struct {
+	id        instance.Id
+	status    instance.InstanceStatus
+	addresses []network.Address
+}
+
+func (s instanceSnapshot) equal(other instanceSnapshot) bool {
+	if s.id != other.id || s.status != other.status || len(s.addresses) != len(other.addresses) {
+		return false
+	}
+	for i, addr := range s.addresses {
+		if addr != other.addresses[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func snapshotInstances(insts []instance.Instance) []instanceSnapshot {
+	snapshots := make([]instanceSnapshot, len(insts))
+	for i, inst := range insts {
+		if inst == nil {
+			continue
+		}
+		snapshots[i].id = inst.Id()
+		snapshots[i].status = inst.Status()
+		// Errors are ignored here; an instance whose addresses
+		// cannot be read is treated as having none.
+		snapshots[i].addresses, _ = inst.Addresses()
+	}
+	return snapshots
+}
+
+func snapshotsEqual(a, b []instanceSnapshot) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
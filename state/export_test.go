@@ -215,6 +215,12 @@ func SetCharmBundleURL(c *gc.C, st *State, curl *charm.URL, bundleURL string) {
 	c.Assert(err, jc.ErrorIsNil)
 }
 
+// AgentHashScheme allows tests to change the scheme used to hash new
+// agent passwords.
+var AgentHashScheme = &agentHashScheme
+
+const AgentHashSchemeSHA512 = agentHashSchemeSHA512
+
 func SetPasswordHash(e Authenticator, passwordHash string) error {
 	type hasSetPasswordHash interface {
 		setPasswordHash(string) error
@@ -80,6 +80,12 @@ type Authenticator interface {
 	PasswordValid(pass string) bool
 }
 
+// PasswordHashUpgrader represents entities whose stored password
+// hashes can be moved to the current hash scheme.
+type PasswordHashUpgrader interface {
+	UpgradePasswordHash(pass string) error
+}
+
 // NotifyWatcherFactory represents an entity that
 // can be watched.
 type NotifyWatcherFactory interface {
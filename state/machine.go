@@ -415,7 +415,11 @@ func (m *Machine) SetPassword(password string) error {
 	if len(password) < utils.MinAgentPasswordLength {
 		return fmt.Errorf("password is only %d bytes long, and is not a valid Agent password", len(password))
 	}
-	return m.setPasswordHash(utils.AgentPasswordHash(password))
+	hash, err := agentPasswordHash(password)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return m.setPasswordHash(hash)
 }
 
 // setPasswordHash sets the underlying password hash in the database directly
@@ -449,8 +453,19 @@ func (m *Machine) getPasswordHash() string {
 // PasswordValid returns whether the given password is valid
 // for the given machine.
 func (m *Machine) PasswordValid(password string) bool {
-	agentHash := utils.AgentPasswordHash(password)
-	return agentHash == m.doc.PasswordHash
+	return compareAgentPasswordHash(password, m.doc.PasswordHash)
+}
+
+// UpgradePasswordHash stores the hash of the given password computed
+// with the current hash scheme, if the stored hash matches the
+// password but was computed with another scheme. Otherwise it does
+// nothing. It is called once a machine agent has logged in.
+func (m *Machine) UpgradePasswordHash(password string) error {
+	hash, upgraded, err := upgradedAgentPasswordHash(m.doc.PasswordHash, password)
+	if err == nil && upgraded {
+		err = m.setPasswordHash(hash)
+	}
+	return errors.Annotatef(err, "cannot upgrade password hash of machine %v", m)
 }
 
 // Destroy sets the machine lifecycle to Dying if it is Alive. It does
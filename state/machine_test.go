@@ -669,6 +669,12 @@ func (s *MachineSuite) TestSetPassword(c *gc.C) {
 	})
 }
 
+func (s *MachineSuite) TestPasswordHashUpgraded(c *gc.C) {
+	testPasswordHashUpgraded(c, s, func() (state.Authenticator, error) {
+		return s.State.Machine(s.machine.Id())
+	})
+}
+
 func (s *MachineSuite) TestMachineWaitAgentPresence(c *gc.C) {
 	alive, err := s.machine.AgentPresence()
 	c.Assert(err, jc.ErrorIsNil)
@@ -76,6 +76,10 @@ type ModelMigration interface {
 	// if the migration is no longer active.
 	SetPhase(nextPhase migration.Phase) error
 
+	// SetPhaseWithStatusMessage sets the phase of the migration, as
+	// SetPhase does, and its status message, in a single transaction.
+	SetPhaseWithStatusMessage(nextPhase migration.Phase, text string) error
+
 	// SetStatusMessage sets some human readable text about the
 	// current progress of the migration.
 	SetStatusMessage(text string) error
@@ -110,6 +114,24 @@ type MinionReports struct {
 	Unknown   []names.Tag
 }
 
+// IllegalPhaseChangeError is returned by ModelMigration.SetPhase when
+// the requested phase cannot be reached from the current phase.
+type IllegalPhaseChangeError struct {
+	From migration.Phase
+	To   migration.Phase
+}
+
+func (e *IllegalPhaseChangeError) Error() string {
+	return fmt.Sprintf("illegal phase change: %s -> %s", e.From, e.To)
+}
+
+// IsIllegalPhaseChangeError returns true if err is an
+// IllegalPhaseChangeError.
+func IsIllegalPhaseChangeError(err error) bool {
+	_, ok := errors.Cause(err).(*IllegalPhaseChangeError)
+	return ok
+}
+
 // modelMigration is an implementation of ModelMigration.
 type modelMigration struct {
 	st        *State
@@ -292,6 +314,17 @@ func (mig *modelMigration) TargetInfo() (*migration.TargetInfo, error) {
 
 // SetPhase implements ModelMigration.
 func (mig *modelMigration) SetPhase(nextPhase migration.Phase) error {
+	return mig.setPhase(nextPhase, nil)
+}
+
+// SetPhaseWithStatusMessage implements ModelMigration.
+func (mig *modelMigration) SetPhaseWithStatusMessage(nextPhase migration.Phase, text string) error {
+	return mig.setPhase(nextPhase, &text)
+}
+
+// setPhase sets the phase of the migration and, if text is not nil,
+// its status message.
+func (mig *modelMigration) setPhase(nextPhase migration.Phase, text *string) error {
 	now := mig.st.clock.Now().UnixNano()
 
 	phase, err := mig.Phase()
@@ -300,10 +333,14 @@ func (mig *modelMigration) SetPhase(nextPhase migration.Phase) error {
 	}
 
 	if nextPhase == phase {
-		return nil // Already at that phase. Nothing to do.
+		// Already at that phase. Only the message may need setting.
+		if text != nil {
+			return mig.SetStatusMessage(*text)
+		}
+		return nil
 	}
 	if !phase.CanTransitionTo(nextPhase) {
-		return errors.Errorf("illegal phase change: %s -> %s", phase, nextPhase)
+		return &IllegalPhaseChangeError{From: phase, To: nextPhase}
 	}
 
 	nextDoc := mig.statusDoc
@@ -317,6 +354,10 @@ func (mig *modelMigration) SetPhase(nextPhase migration.Phase) error {
 		nextDoc.SuccessTime = now
 		update["success-time"] = now
 	}
+	if text != nil {
+		nextDoc.StatusMessage = *text
+		update["status-message"] = *text
+	}
 	var ops []txn.Op
 
 	// If the migration aborted, make the model active again.
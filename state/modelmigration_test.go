@@ -459,6 +459,7 @@ func (s *MigrationSuite) TestIllegalPhaseTransition(c *gc.C) {
 
 	err = mig.SetPhase(migration.SUCCESS)
 	c.Check(err, gc.ErrorMatches, "illegal phase change: QUIESCE -> SUCCESS")
+	c.Check(state.IsIllegalPhaseChangeError(err), jc.IsTrue)
 }
 
 func (s *MigrationSuite) TestPhaseChangeRace(c *gc.C) {
@@ -501,6 +502,31 @@ func (s *MigrationSuite) TestStatusMessage(c *gc.C) {
 	c.Check(mig2.StatusMessage(), gc.Equals, "foo bar")
 }
 
+func (s *MigrationSuite) TestSetPhaseWithStatusMessage(c *gc.C) {
+	mig, err := s.State2.CreateMigration(s.stdSpec)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = mig.SetPhaseWithStatusMessage(migration.ABORT, "aborted: boom")
+	c.Assert(err, jc.ErrorIsNil)
+	assertPhase(c, mig, migration.ABORT)
+	c.Check(mig.StatusMessage(), gc.Equals, "aborted: boom")
+
+	mig2, err := s.State2.LatestMigration()
+	c.Assert(err, jc.ErrorIsNil)
+	assertPhase(c, mig2, migration.ABORT)
+	c.Check(mig2.StatusMessage(), gc.Equals, "aborted: boom")
+}
+
+func (s *MigrationSuite) TestSetPhaseWithStatusMessageIllegal(c *gc.C) {
+	mig, err := s.State2.CreateMigration(s.stdSpec)
+	c.Assert(err, jc.ErrorIsNil)
+
+	err = mig.SetPhaseWithStatusMessage(migration.SUCCESS, "done")
+	c.Check(err, gc.ErrorMatches, "illegal phase change: QUIESCE -> SUCCESS")
+	c.Assert(mig.Refresh(), jc.ErrorIsNil)
+	c.Check(mig.StatusMessage(), gc.Equals, "starting")
+}
+
 func (s *MigrationSuite) TestWatchForMigration(c *gc.C) {
 	// Start watching for migration.
 	w, wc := s.createMigrationWatcher(c, s.State2)
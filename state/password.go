@@ -0,0 +1,226 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/juju/errors"
+	"github.com/juju/utils"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// defaultUserPasswordIterations is the PBKDF2 iteration count
+// used by utils.UserPasswordHash.
+const defaultUserPasswordIterations = 8192
+
+// Bounds on the PBKDF2 iteration count accepted by
+// SetUserPasswordIterations.
+const (
+	MinUserPasswordIterations = 1000
+	MaxUserPasswordIterations = 1 << 20
+)
+
+// userPasswordIterations is the PBKDF2 iteration count used when
+// hashing new user passwords. Hashes computed with any other count
+// than the default are prefixed with the count, so that they can
+// still be verified after the count is changed again.
+//
+// Changing this breaks compatibility with anything that expects a
+// bare hash, as produced by utils.UserPasswordHash.
+var userPasswordIterations = defaultUserPasswordIterations
+
+// UserPasswordIterations returns the PBKDF2 iteration count used when
+// hashing new user passwords.
+func UserPasswordIterations() int {
+	return userPasswordIterations
+}
+
+// SetUserPasswordIterations sets the PBKDF2 iteration count used when
+// hashing new user passwords, which must be between
+// MinUserPasswordIterations and MaxUserPasswordIterations. Existing
+// hashes can still be verified after the count changes.
+//
+// It is not safe to call while passwords are being hashed, so it
+// should only be called before any State is opened.
+func SetUserPasswordIterations(iter int) error {
+	if iter < MinUserPasswordIterations || iter > MaxUserPasswordIterations {
+		return errors.NotValidf(
+			"user password iteration count %d (must be between %d and %d)",
+			iter, MinUserPasswordIterations, MaxUserPasswordIterations,
+		)
+	}
+	userPasswordIterations = iter
+	return nil
+}
+
+// userPasswordHash returns the hash of the given user password and
+// salt, computed with userPasswordIterations.
+func userPasswordHash(password, salt string) string {
+	return userPasswordHashWithCost(password, salt, userPasswordIterations)
+}
+
+// userPasswordHashWithCost returns the hash of the given user password
+// and salt, computed with the given PBKDF2 iteration count. If the
+// count is the default, the hash is identical to that returned by
+// utils.UserPasswordHash; otherwise it is prefixed with "<count>$".
+func userPasswordHashWithCost(password, salt string, iter int) string {
+	if iter == defaultUserPasswordIterations {
+		return utils.UserPasswordHash(password, salt)
+	}
+	// Generate 18 bytes, as utils.UserPasswordHash does,
+	// so there are no base64 padding characters.
+	key := pbkdf2.Key([]byte(password), []byte(salt), iter, 18, sha512.New)
+	return fmt.Sprintf("%d$%s", iter, base64.StdEncoding.EncodeToString(key))
+}
+
+// Agent password hash schemes. Hashes computed with any scheme other
+// than agentHashSchemeLegacy are prefixed with "<scheme>$", so that
+// the scheme can be changed without invalidating stored hashes.
+const (
+	// agentHashSchemeLegacy identifies unprefixed hashes, as computed
+	// by utils.AgentPasswordHash: a SHA-512 truncated to 18 bytes.
+	agentHashSchemeLegacy = ""
+
+	// agentHashSchemeSHA512 identifies salted hashes of the form
+	// "1$<salt>$<hash>", where hash is the full SHA-512 of the salt
+	// followed by the password.
+	agentHashSchemeSHA512 = "1"
+)
+
+// agentHashScheme is the scheme used when hashing new agent passwords.
+//
+// It remains agentHashSchemeLegacy until every controller that may
+// read the hashes can verify the newer schemes. Older controllers
+// compare stored hashes with utils.AgentPasswordHash directly, so
+// agents whose hashes had been written with another scheme could not
+// log in after a downgrade, or after their model was migrated to such
+// a controller. Once the scheme is changed, stored hashes are upgraded
+// as agents log in; see upgradedAgentPasswordHash.
+var agentHashScheme = agentHashSchemeLegacy
+
+// agentPasswordHash returns the hash of the given agent password,
+// computed with agentHashScheme.
+func agentPasswordHash(password string) (string, error) {
+	salt, err := agentPasswordSalt(agentHashScheme)
+	if err != nil {
+		return "", errors.Trace(err)
+	}
+	hash, _ := agentPasswordHashWithScheme(password, agentHashScheme, salt)
+	return hash, nil
+}
+
+// agentPasswordSalt returns a new salt for hashing an agent password
+// with the given scheme, or "" if the scheme is unsalted.
+func agentPasswordSalt(scheme string) (string, error) {
+	if scheme == agentHashSchemeLegacy {
+		return "", nil
+	}
+	return utils.RandomSalt()
+}
+
+// agentPasswordHashWithScheme returns the hash of the given agent
+// password computed with the given scheme and salt, and whether the
+// scheme is known. The salt is ignored by unsalted schemes.
+func agentPasswordHashWithScheme(password, scheme, salt string) (string, bool) {
+	switch scheme {
+	case agentHashSchemeLegacy:
+		return utils.AgentPasswordHash(password), true
+	case agentHashSchemeSHA512:
+		sum := sha512.Sum512([]byte(salt + password))
+		return scheme + "$" + salt + "$" + base64.StdEncoding.EncodeToString(sum[:]), true
+	}
+	return "", false
+}
+
+// parseAgentPasswordHash returns the scheme the given agent password
+// hash was computed with, and the salt it was computed with, if any.
+func parseAgentPasswordHash(hash string) (scheme, salt string) {
+	parts := strings.SplitN(hash, "$", 3)
+	switch len(parts) {
+	case 1:
+		return agentHashSchemeLegacy, ""
+	case 2:
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// compareAgentPasswordHash reports whether the given password
+// matches a hash previously computed with agentPasswordHash or
+// utils.AgentPasswordHash, whatever scheme it was computed with.
+// The hashes are compared in constant time, so that the time taken
+// does not reveal how much of the hash matched.
+func compareAgentPasswordHash(password, hash string) bool {
+	scheme, salt := parseAgentPasswordHash(hash)
+	expect, ok := agentPasswordHashWithScheme(password, scheme, salt)
+	if !ok {
+		return false
+	}
+	return hashesEqual(expect, hash)
+}
+
+// upgradedAgentPasswordHash returns the hash of the given agent
+// password computed with the current scheme, and true, if the stored
+// hash was computed with another scheme and matches the password.
+// Otherwise it returns the stored hash unchanged, and false.
+//
+// It is used by the UpgradePasswordHash methods of machines and units,
+// which the agent authenticator calls after a successful login, so
+// that stored hashes move to a new scheme as agents log in.
+func upgradedAgentPasswordHash(stored, plaintext string) (string, bool, error) {
+	if scheme, _ := parseAgentPasswordHash(stored); scheme == agentHashScheme {
+		return stored, false, nil
+	}
+	if !compareAgentPasswordHash(plaintext, stored) {
+		return stored, false, nil
+	}
+	hash, err := agentPasswordHash(plaintext)
+	if err != nil {
+		return stored, false, errors.Trace(err)
+	}
+	return hash, true, nil
+}
+
+// compareUserPasswordHash reports whether the given password matches
+// a hash previously computed with userPasswordHash and the given salt,
+// whatever iteration count it was computed with. The hashes are
+// compared in constant time, as for compareAgentPasswordHash.
+func compareUserPasswordHash(password, salt, hash string) bool {
+	iter := defaultUserPasswordIterations
+	if i := strings.Index(hash, "$"); i >= 0 {
+		n, err := strconv.Atoi(hash[:i])
+		if err != nil || n <= 0 {
+			return false
+		}
+		iter = n
+	}
+	return hashesEqual(userPasswordHashWithCost(password, salt, iter), hash)
+}
+
+// PasswordHashFingerprint returns a short identifier of the schemes
+// and parameters currently used to hash new agent and user passwords.
+// It changes whenever those do, so a hash stored alongside the
+// fingerprint that was current when it was computed can later be
+// found to be stale. Salts are per user, so are not included. Hashes
+// themselves are unaffected.
+func PasswordHashFingerprint() string {
+	params := fmt.Sprintf(
+		"agent-scheme=%q user-pbkdf2-sha512-iterations=%d",
+		agentHashScheme, userPasswordIterations,
+	)
+	sum := sha256.Sum256([]byte(params))
+	return hex.EncodeToString(sum[:6])
+}
+
+func hashesEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
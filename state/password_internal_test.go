@@ -0,0 +1,159 @@
+// Copyright 2016 Canonical Ltd.
+// Licensed under the AGPLv3, see LICENCE file for details.
+
+package state
+
+import (
+	"github.com/juju/errors"
+	"github.com/juju/testing"
+	jc "github.com/juju/testing/checkers"
+	"github.com/juju/utils"
+	gc "gopkg.in/check.v1"
+)
+
+type passwordSuite struct {
+	testing.IsolationSuite
+}
+
+var _ = gc.Suite(&passwordSuite{})
+
+func (*passwordSuite) TestCompareAgentPasswordHash(c *gc.C) {
+	hash := utils.AgentPasswordHash("foo-12345678901234567890")
+	c.Assert(compareAgentPasswordHash("foo-12345678901234567890", hash), jc.IsTrue)
+	c.Assert(compareAgentPasswordHash("bar-12345678901234567890", hash), jc.IsFalse)
+	c.Assert(compareAgentPasswordHash("foo-12345678901234567890", ""), jc.IsFalse)
+}
+
+func (*passwordSuite) TestAgentPasswordHashLegacy(c *gc.C) {
+	// New hashes stay readable by controllers that only know the
+	// legacy scheme.
+	hash, err := agentPasswordHash("foo-12345678901234567890")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hash, gc.Equals, utils.AgentPasswordHash("foo-12345678901234567890"))
+}
+
+func (s *passwordSuite) TestAgentPasswordHashSHA512(c *gc.C) {
+	s.PatchValue(&agentHashScheme, agentHashSchemeSHA512)
+	hash, err := agentPasswordHash("foo-12345678901234567890")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hash, gc.Matches, `1\$[A-Za-z0-9+/]{24}\$[A-Za-z0-9+/]{86}==`)
+	c.Assert(compareAgentPasswordHash("foo-12345678901234567890", hash), jc.IsTrue)
+	c.Assert(compareAgentPasswordHash("bar-12345678901234567890", hash), jc.IsFalse)
+
+	// Each hash has its own salt.
+	hash2, err := agentPasswordHash("foo-12345678901234567890")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(hash2, gc.Not(gc.Equals), hash)
+	c.Assert(compareAgentPasswordHash("foo-12345678901234567890", hash2), jc.IsTrue)
+}
+
+func (s *passwordSuite) TestCompareAgentPasswordHashUnknownScheme(c *gc.C) {
+	s.PatchValue(&agentHashScheme, agentHashSchemeSHA512)
+	hash, err := agentPasswordHash("foo-12345678901234567890")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(compareAgentPasswordHash("foo-12345678901234567890", "x"+hash), jc.IsFalse)
+}
+
+func (s *passwordSuite) TestUpgradedAgentPasswordHash(c *gc.C) {
+	s.PatchValue(&agentHashScheme, agentHashSchemeSHA512)
+	legacy := utils.AgentPasswordHash("foo-12345678901234567890")
+	hash, upgraded, err := upgradedAgentPasswordHash(legacy, "foo-12345678901234567890")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(upgraded, jc.IsTrue)
+	c.Assert(hash, gc.Matches, `1\$.*`)
+	c.Assert(compareAgentPasswordHash("foo-12345678901234567890", hash), jc.IsTrue)
+}
+
+func (s *passwordSuite) TestUpgradedAgentPasswordHashWrongPassword(c *gc.C) {
+	s.PatchValue(&agentHashScheme, agentHashSchemeSHA512)
+	legacy := utils.AgentPasswordHash("foo-12345678901234567890")
+	hash, upgraded, err := upgradedAgentPasswordHash(legacy, "bar-12345678901234567890")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(upgraded, jc.IsFalse)
+	c.Assert(hash, gc.Equals, legacy)
+}
+
+func (*passwordSuite) TestUpgradedAgentPasswordHashCurrentScheme(c *gc.C) {
+	current := utils.AgentPasswordHash("foo-12345678901234567890")
+	hash, upgraded, err := upgradedAgentPasswordHash(current, "foo-12345678901234567890")
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(upgraded, jc.IsFalse)
+	c.Assert(hash, gc.Equals, current)
+}
+
+func (*passwordSuite) TestCompareUserPasswordHash(c *gc.C) {
+	hash := utils.UserPasswordHash("secret", "salt")
+	c.Assert(compareUserPasswordHash("secret", "salt", hash), jc.IsTrue)
+	c.Assert(compareUserPasswordHash("secret", "pepper", hash), jc.IsFalse)
+	c.Assert(compareUserPasswordHash("wrong", "salt", hash), jc.IsFalse)
+}
+
+func (*passwordSuite) TestUserPasswordHashWithCostDefault(c *gc.C) {
+	hash := userPasswordHashWithCost("secret", "salt", defaultUserPasswordIterations)
+	c.Assert(hash, gc.Equals, utils.UserPasswordHash("secret", "salt"))
+}
+
+func (*passwordSuite) TestUserPasswordHashWithCost(c *gc.C) {
+	hash := userPasswordHashWithCost("secret", "salt", 100)
+	c.Assert(hash, gc.Matches, `100\$[A-Za-z0-9+/]{24}`)
+	c.Assert(compareUserPasswordHash("secret", "salt", hash), jc.IsTrue)
+	c.Assert(compareUserPasswordHash("wrong", "salt", hash), jc.IsFalse)
+}
+
+func (*passwordSuite) TestCompareUserPasswordHashChangedCost(c *gc.C) {
+	oldHash := userPasswordHash("secret", "salt")
+	defer func(iter int) {
+		userPasswordIterations = iter
+	}(userPasswordIterations)
+	userPasswordIterations = 100
+	newHash := userPasswordHash("secret", "salt")
+	c.Assert(newHash, gc.Not(gc.Equals), oldHash)
+
+	// Hashes made with either cost can be verified.
+	c.Assert(compareUserPasswordHash("secret", "salt", oldHash), jc.IsTrue)
+	c.Assert(compareUserPasswordHash("secret", "salt", newHash), jc.IsTrue)
+}
+
+func (*passwordSuite) TestSetUserPasswordIterations(c *gc.C) {
+	defer func(iter int) {
+		userPasswordIterations = iter
+	}(userPasswordIterations)
+	c.Assert(UserPasswordIterations(), gc.Equals, defaultUserPasswordIterations)
+
+	err := SetUserPasswordIterations(20000)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(UserPasswordIterations(), gc.Equals, 20000)
+	hash := userPasswordHash("secret", "salt")
+	c.Assert(hash, gc.Matches, `20000\$[A-Za-z0-9+/]{24}`)
+	c.Assert(compareUserPasswordHash("secret", "salt", hash), jc.IsTrue)
+}
+
+func (*passwordSuite) TestSetUserPasswordIterationsOutOfRange(c *gc.C) {
+	defer func(iter int) {
+		userPasswordIterations = iter
+	}(userPasswordIterations)
+	for _, iter := range []int{-1, 0, MinUserPasswordIterations - 1, MaxUserPasswordIterations + 1} {
+		c.Logf("iterations %d", iter)
+		err := SetUserPasswordIterations(iter)
+		c.Check(err, gc.ErrorMatches, `user password iteration count -?\d+ \(must be between 1000 and 1048576\) not valid`)
+		c.Check(err, jc.Satisfies, errors.IsNotValid)
+		c.Check(UserPasswordIterations(), gc.Equals, defaultUserPasswordIterations)
+	}
+}
+
+func (*passwordSuite) TestCompareUserPasswordHashInvalidCost(c *gc.C) {
+	c.Assert(compareUserPasswordHash("secret", "salt", "x$abc"), jc.IsFalse)
+	c.Assert(compareUserPasswordHash("secret", "salt", "-1$abc"), jc.IsFalse)
+}
+
+func (*passwordSuite) TestPasswordHashFingerprint(c *gc.C) {
+	fingerprint := PasswordHashFingerprint()
+	c.Assert(fingerprint, gc.Matches, `[0-9a-f]{12}`)
+	c.Assert(PasswordHashFingerprint(), gc.Equals, fingerprint)
+
+	defer func(iter int) {
+		userPasswordIterations = iter
+	}(userPasswordIterations)
+	userPasswordIterations = 10000
+	c.Assert(PasswordHashFingerprint(), gc.Not(gc.Equals), fingerprint)
+}
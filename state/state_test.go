@@ -2721,6 +2721,42 @@ func testSetPassword(c *gc.C, getEntity func() (state.Authenticator, error)) {
 	}
 }
 
+// testPasswordHashUpgraded checks that a legacy agent password hash
+// is upgraded to the current scheme by UpgradePasswordHash, and not
+// by PasswordValid.
+func testPasswordHashUpgraded(c *gc.C, patcher interface {
+	PatchValue(dest, value interface{})
+}, getEntity func() (state.Authenticator, error)) {
+	e, err := getEntity()
+	c.Assert(err, jc.ErrorIsNil)
+	err = state.SetPasswordHash(e, utils.AgentPasswordHash(goodPassword))
+	c.Assert(err, jc.ErrorIsNil)
+
+	patcher.PatchValue(state.AgentHashScheme, state.AgentHashSchemeSHA512)
+	legacy := utils.AgentPasswordHash(goodPassword)
+	upgrader := e.(state.PasswordHashUpgrader)
+
+	// Validating a password never changes the stored hash.
+	c.Assert(e.PasswordValid(goodPassword), jc.IsTrue)
+	c.Assert(state.GetPasswordHash(e), gc.Equals, legacy)
+
+	// Nor does upgrading with the wrong password.
+	err = upgrader.UpgradePasswordHash(alternatePassword)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(state.GetPasswordHash(e), gc.Equals, legacy)
+
+	err = upgrader.UpgradePasswordHash(goodPassword)
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(state.GetPasswordHash(e), gc.Matches, `1\$.*`)
+	c.Assert(e.PasswordValid(goodPassword), jc.IsTrue)
+
+	// Check the upgraded hash was stored.
+	e2, err := getEntity()
+	c.Assert(err, jc.ErrorIsNil)
+	c.Assert(state.GetPasswordHash(e2), gc.Equals, state.GetPasswordHash(e))
+	c.Assert(e2.PasswordValid(goodPassword), jc.IsTrue)
+}
+
 type entity interface {
 	state.Entity
 	state.Lifer
@@ -264,7 +264,11 @@ func (u *Unit) SetPassword(password string) error {
 	if len(password) < utils.MinAgentPasswordLength {
 		return fmt.Errorf("password is only %d bytes long, and is not a valid Agent password", len(password))
 	}
-	return u.setPasswordHash(utils.AgentPasswordHash(password))
+	hash, err := agentPasswordHash(password)
+	if err != nil {
+		return errors.Trace(err)
+	}
+	return u.setPasswordHash(hash)
 }
 
 // setPasswordHash sets the underlying password hash in the database directly
@@ -295,11 +299,19 @@ func (u *Unit) getPasswordHash() string {
 // PasswordValid returns whether the given password is valid
 // for the given unit.
 func (u *Unit) PasswordValid(password string) bool {
-	agentHash := utils.AgentPasswordHash(password)
-	if agentHash == u.doc.PasswordHash {
-		return true
+	return compareAgentPasswordHash(password, u.doc.PasswordHash)
+}
+
+// UpgradePasswordHash stores the hash of the given password computed
+// with the current hash scheme, if the stored hash matches the
+// password but was computed with another scheme. Otherwise it does
+// nothing. It is called once a unit agent has logged in.
+func (u *Unit) UpgradePasswordHash(password string) error {
+	hash, upgraded, err := upgradedAgentPasswordHash(u.doc.PasswordHash, password)
+	if err == nil && upgraded {
+		err = u.setPasswordHash(hash)
 	}
-	return false
+	return errors.Annotatef(err, "cannot upgrade password hash of unit %v", u)
 }
 
 // Destroy, when called on a Alive unit, advances its lifecycle as far as
@@ -868,6 +868,12 @@ func (s *UnitSuite) TestSetPassword(c *gc.C) {
 	})
 }
 
+func (s *UnitSuite) TestPasswordHashUpgraded(c *gc.C) {
+	testPasswordHashUpgraded(c, s, func() (state.Authenticator, error) {
+		return s.State.Unit(s.unit.Name())
+	})
+}
+
 func (s *UnitSuite) TestUnitSetAgentPresence(c *gc.C) {
 	alive, err := s.unit.AgentPresence()
 	c.Assert(err, jc.ErrorIsNil)
@@ -90,7 +90,7 @@ func (st *State) addUser(name, displayName, password, creator string, secretKey
 		if err != nil {
 			return nil, err
 		}
-		user.doc.PasswordHash = utils.UserPasswordHash(password, salt)
+		user.doc.PasswordHash = userPasswordHash(password, salt)
 		user.doc.PasswordSalt = salt
 	}
 
@@ -156,7 +156,7 @@ func createInitialUserOps(controllerUUID string, user names.UserTag, password, s
 		DocID:        nameToLower,
 		Name:         user.Name(),
 		DisplayName:  user.Name(),
-		PasswordHash: utils.UserPasswordHash(password, salt),
+		PasswordHash: userPasswordHash(password, salt),
 		PasswordSalt: salt,
 		CreatedBy:    user.Name(),
 		DateCreated:  dateCreated,
@@ -411,7 +411,7 @@ func (u *User) SetPassword(password string) error {
 	if err != nil {
 		return err
 	}
-	return u.SetPasswordHash(utils.UserPasswordHash(password, salt), salt)
+	return u.SetPasswordHash(userPasswordHash(password, salt), salt)
 }
 
 // SetPasswordHash stores the hash and the salt of the
@@ -459,7 +459,7 @@ func (u *User) PasswordValid(password string) bool {
 		return false
 	}
 	if u.doc.PasswordSalt != "" {
-		return utils.UserPasswordHash(password, u.doc.PasswordSalt) == u.doc.PasswordHash
+		return compareUserPasswordHash(password, u.doc.PasswordSalt, u.doc.PasswordHash)
 	}
 	return false
 }
@@ -5,6 +5,9 @@ package migrationmaster
 
 import (
 	"github.com/juju/errors"
+	"github.com/juju/utils/clock"
+
+	"github.com/juju/juju/api"
 	"github.com/juju/juju/api/base"
 	"github.com/juju/juju/api/migrationmaster"
 	"github.com/juju/juju/api/watcher"
@@ -12,7 +15,17 @@ import (
 )
 
 func NewFacade(apiCaller base.APICaller) (Facade, error) {
-	facade := migrationmaster.NewClient(apiCaller, watcher.NewNotifyWatcher)
+	facade, err := migrationmaster.NewClientWithHeartbeat(
+		apiCaller, watcher.NewNotifyWatcher,
+		migrationmaster.HeartbeatConfig{
+			Clock:   clock.WallClock,
+			Period:  api.PingPeriod,
+			Timeout: api.PingTimeout,
+		},
+	)
+	if err != nil {
+		return nil, errors.Trace(err)
+	}
 	return facade, nil
 }
 